@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	imagedraw "image/draw"
+	"image/gif"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
+
+	"os_project/trackstore"
+)
+
+// 回放动画参数
+type animateOptions struct {
+	Frames    int           // 总帧数
+	FPS       int           // 每秒帧数
+	Tail      time.Duration // 只显示最近这段时间内的轨迹，0 表示显示全部已走过的轨迹
+	Timestamp bool          // 在每帧标题中显示当前时间
+}
+
+// 动画样式：完整轨迹作为浅灰色底图，已走过的部分为蓝色，当前位置为红点
+var (
+	routeLineStyle = draw.LineStyle{Color: color.RGBA{R: 210, G: 210, B: 210, A: 255}, Width: vg.Points(1)}
+	trailLineStyle = draw.LineStyle{Color: color.RGBA{R: 21, G: 101, B: 192, A: 255}, Width: vg.Points(2)}
+	headGlyphStyle = draw.GlyphStyle{Color: color.RGBA{R: 211, G: 47, B: 47, A: 255}, Radius: vg.Points(4), Shape: draw.CircleGlyph{}}
+)
+
+// 逐帧绘制轨迹的行进过程。plotOpts.Format 为 gif 时输出 GIF 动画到 outPath，
+// 否则将其作为目录，写入 frame_0001.png 起编号的 PNG 帧
+func execANIMATE(ctx context.Context, directory, outPath string, q trackstore.Query, opts animateOptions, plotOpts plotOptions) error {
+	store, err := trackstore.Open(directory, trackstore.DefaultOptions())
+	if err != nil {
+		return fmt.Errorf("读取索引表失败: %v", err)
+	}
+
+	var points []trackstore.Point
+	for p, err := range store.Query(ctx, q) {
+		if err != nil {
+			return err
+		}
+		points = append(points, p)
+	}
+	if len(points) < 2 {
+		return fmt.Errorf("轨迹 %q 在给定时间范围内的点少于 2 个", q.Trajectory)
+	}
+
+	// 所有点都有定位时间时按时间均匀取帧，否则按点的顺序均匀取帧
+	timed := true
+	for _, p := range points {
+		if p.Time.IsZero() {
+			timed = false
+			break
+		}
+	}
+	if !timed && (opts.Tail > 0 || opts.Timestamp) {
+		log.Println("部分点没有定位时间，按点的顺序取帧，忽略 -tail 与 -timestamp")
+	}
+
+	proj, err := plotProjection(plotOpts.Projection, points)
+	if err != nil {
+		return err
+	}
+	route := make(plotter.XYs, len(points))
+	for i, p := range points {
+		route[i].X, route[i].Y = plotXY(proj, p)
+	}
+	xmin, xmax, ymin, ymax := plotter.XYRange(route)
+
+	gifMode := plotOpts.Format == "gif"
+	if !gifMode {
+		if err := os.MkdirAll(outPath, os.ModePerm); err != nil {
+			return fmt.Errorf("创建目录失败: %v", err)
+		}
+	}
+	anim := &gif.GIF{}
+	delay := max(1, 100/opts.FPS) // GIF 的帧间隔单位为 1/100 秒
+
+	for frame := 0; frame < opts.Frames; frame++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		first, last, now := frameWindow(points, frame, opts, timed)
+
+		title := plotOpts.Title
+		if timed && opts.Timestamp {
+			title = strings.TrimSpace(title + "  " + now.Format("2006-01-02 15:04:05"))
+		}
+		p, err := animationFrame(route, first, last, title, proj)
+		if err != nil {
+			return err
+		}
+		// 固定坐标范围，避免画面随已走过的部分缩放
+		p.X.Min, p.X.Max, p.Y.Min, p.Y.Max = xmin, xmax, ymin, ymax
+
+		c := vgimg.New(plotOpts.Width, plotOpts.Height)
+		p.Draw(draw.New(c))
+
+		if gifMode {
+			img := c.Image()
+			frameImg := image.NewPaletted(img.Bounds(), palette.Plan9)
+			imagedraw.Draw(frameImg, img.Bounds(), img, image.Point{}, imagedraw.Src)
+			anim.Image = append(anim.Image, frameImg)
+			anim.Delay = append(anim.Delay, delay)
+			continue
+		}
+		if err := saveFramePNG(filepath.Join(outPath, fmt.Sprintf("frame_%04d.png", frame+1)), c.Image()); err != nil {
+			return err
+		}
+	}
+
+	if !gifMode {
+		log.Printf("%d 帧保存到目录 %s", opts.Frames, outPath)
+		return nil
+	}
+	file, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("创建文件失败: %v", err)
+	}
+	defer file.Close()
+	if err := gif.EncodeAll(file, anim); err != nil {
+		return fmt.Errorf("写入 GIF 失败: %v", err)
+	}
+	log.Printf("%d 帧动画保存为 %s", opts.Frames, outPath)
+	return file.Close()
+}
+
+// 第 frame 帧显示的点为 points[first:last]，timed 时 now 为该帧对应的时间。
+// 所有点都有定位时间时按时间均匀取帧，否则按点的顺序均匀取帧
+func frameWindow(points []trackstore.Point, frame int, opts animateOptions, timed bool) (first, last int, now time.Time) {
+	progress := 1.0
+	if opts.Frames > 1 {
+		progress = float64(frame) / float64(opts.Frames-1)
+	}
+
+	first, last = 0, 1+int(progress*float64(len(points)-1))
+	if !timed {
+		return first, last, now
+	}
+	start, end := points[0].Time, points[len(points)-1].Time
+	now = start.Add(time.Duration(progress * float64(end.Sub(start))))
+	for last < len(points) && !points[last].Time.After(now) {
+		last++
+	}
+	for last > 1 && points[last-1].Time.After(now) {
+		last--
+	}
+	if opts.Tail > 0 {
+		for first < last-1 && points[first].Time.Before(now.Add(-opts.Tail)) {
+			first++
+		}
+	}
+	return first, last, now
+}
+
+// 一帧：完整轨迹底图、route[first:last] 的轨迹与当前位置
+func animationFrame(route plotter.XYs, first, last int, title string, proj trackstore.Projection) (*plot.Plot, error) {
+	p := plot.New()
+	p.Title.Text = title
+	setAxisLabels(p, proj)
+
+	background, err := plotter.NewLine(route)
+	if err != nil {
+		return nil, fmt.Errorf("创建折线失败: %v", err)
+	}
+	background.LineStyle = routeLineStyle
+	p.Add(background)
+
+	if last-first > 1 {
+		trail, err := plotter.NewLine(route[first:last])
+		if err != nil {
+			return nil, fmt.Errorf("创建折线失败: %v", err)
+		}
+		trail.LineStyle = trailLineStyle
+		p.Add(trail)
+	}
+
+	head, err := plotter.NewScatter(route[last-1 : last])
+	if err != nil {
+		return nil, fmt.Errorf("创建散点图失败: %v", err)
+	}
+	head.GlyphStyle = headGlyphStyle
+	p.Add(head)
+	return p, nil
+}
+
+func saveFramePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建文件失败: %v", err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		return fmt.Errorf("写入 PNG 失败: %v", err)
+	}
+	return file.Close()
+}
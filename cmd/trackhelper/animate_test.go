@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"image/gif"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gonum.org/v1/plot/vg"
+
+	"os_project/trackstore"
+)
+
+func TestFrameWindow(t *testing.T) {
+	// 10 个点，每 10 秒一个
+	start := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	points := linePoints(10, 120.0)
+	for i := range points {
+		points[i].Time = start.Add(time.Duration(i) * 10 * time.Second)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		frame       int
+		opts        animateOptions
+		timed       bool
+		first, last int
+		now         time.Duration // 相对 start，timed 时检查
+	}{
+		{"第一帧", 0, animateOptions{Frames: 10}, true, 0, 1, 0},
+		{"最后一帧", 9, animateOptions{Frames: 10}, true, 0, 10, 90 * time.Second},
+		{"中间一帧", 5, animateOptions{Frames: 10}, true, 0, 6, 50 * time.Second},
+		{"两点之间", 1, animateOptions{Frames: 4}, true, 0, 4, 30 * time.Second},
+		{"只显示最近 20 秒", 9, animateOptions{Frames: 10, Tail: 20 * time.Second}, true, 7, 10, 90 * time.Second},
+		{"只有一帧", 0, animateOptions{Frames: 1}, true, 0, 10, 90 * time.Second},
+		{"按点的顺序", 2, animateOptions{Frames: 4, Tail: 20 * time.Second}, false, 0, 7, 0},
+	} {
+		first, last, now := frameWindow(points, tc.frame, tc.opts, tc.timed)
+		if first != tc.first || last != tc.last {
+			t.Errorf("%s: 显示范围应为 [%d, %d)，实际 [%d, %d)", tc.name, tc.first, tc.last, first, last)
+		}
+		if tc.timed && !now.Equal(start.Add(tc.now)) {
+			t.Errorf("%s: 帧时间应为 %v，实际 %v", tc.name, start.Add(tc.now), now)
+		}
+		if !tc.timed && !now.IsZero() {
+			t.Errorf("%s: 没有定位时间时帧时间应为零值，实际 %v", tc.name, now)
+		}
+	}
+}
+
+func TestExecAnimate(t *testing.T) {
+	dir := t.TempDir()
+	store, err := trackstore.Open(filepath.Join(dir, "data"), trackstore.DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	start := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	points := linePoints(20, 120.0)
+	for i := range points {
+		points[i].Time = start.Add(time.Duration(i) * time.Second)
+	}
+	if _, err := store.Append(context.Background(), "a", points); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	store.Close()
+
+	q := trackstore.Query{Trajectory: "a"}
+	opts := animateOptions{Frames: 3, FPS: 10, Timestamp: true}
+	plotOpts := plotOptions{Title: "test", Width: vg.Inch, Height: vg.Inch, Projection: "webmercator"}
+
+	framesDir := filepath.Join(dir, "frames")
+	plotOpts.Format = "png"
+	if err := execANIMATE(context.Background(), filepath.Join(dir, "data"), framesDir, q, opts, plotOpts); err != nil {
+		t.Fatalf("生成 PNG 帧失败: %v", err)
+	}
+	entries, _ := os.ReadDir(framesDir)
+	if len(entries) != 3 || entries[0].Name() != "frame_0001.png" || entries[2].Name() != "frame_0003.png" {
+		t.Errorf("PNG 帧不正确: %v", entries)
+	}
+
+	gifPath := filepath.Join(dir, "track.gif")
+	plotOpts.Format = "gif"
+	if err := execANIMATE(context.Background(), filepath.Join(dir, "data"), gifPath, q, opts, plotOpts); err != nil {
+		t.Fatalf("生成 GIF 失败: %v", err)
+	}
+	file, err := os.Open(gifPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	anim, err := gif.DecodeAll(file)
+	if err != nil {
+		t.Fatalf("解析 GIF 失败: %v", err)
+	}
+	if len(anim.Image) != 3 || anim.Delay[0] != 10 {
+		t.Errorf("GIF 应有 3 帧、帧间隔 10，实际 %d 帧 %v", len(anim.Image), anim.Delay)
+	}
+
+	if err := execANIMATE(context.Background(), filepath.Join(dir, "data"), gifPath, trackstore.Query{Trajectory: "b"}, opts, plotOpts); err == nil {
+		t.Errorf("轨迹不存在时应报错")
+	}
+}
@@ -0,0 +1,707 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gonum.org/v1/plot/vg"
+
+	"os_project/trackrpc"
+	"os_project/trackstore"
+)
+
+// 子命令
+type command struct {
+	Name  string
+	Args  string // 位置参数说明
+	Desc  string
+	Run   func(ctx context.Context, args []string) error
+}
+
+func commandList() []command {
+	return []command{
+		{"store", "SOURCE DEST", "读取 XLSX 或 GPX 轨迹文件 SOURCE，清洗后分块追加到目录 DEST（不存在时自动创建）", cmdStore},
+		{"read", "DIR [POINT...]", "查询目录 DIR 中包含给定点 \"(经度,纬度)\" 的数据块、给定点周围或多边形内的点、最近的 K 个点，并绘制轨迹图", cmdRead},
+		{"heatmap", "DIR", "将目录 DIR 中的轨迹点按墨卡托格网统计密度或停留时间，绘制热力图并可导出格网", cmdHeatmap},
+		{"animate", "DIR", "逐帧回放目录 DIR 中一条轨迹的行进过程，输出 GIF 动画或 PNG 帧序列", cmdAnimate},
+		{"export", "DIR", "将目录 DIR 中的全部轨迹点导出为 CSV 或 JSON", cmdExport},
+		{"serve", "DIR", "加载目录 DIR 中的存储并提供 HTTP/JSON 查询服务，可选提供 gRPC 写入与查询服务", cmdServe},
+		{"reindex", "DIR", "根据目录 DIR 中的数据块文件重建 IndexTable.gob，原索引表保留为 IndexTable.gob.bak", cmdReindex},
+		{"migrate", "DIR", "将旧版本程序写入的目录 DIR 原地升级到当前格式", cmdMigrate},
+	}
+}
+
+// 参数错误，退出码为 2
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func printHelp() {
+	fmt.Fprintln(os.Stderr, "Usage: TrackHelper COMMAND [flags] ARGS...")
+	fmt.Fprintln(os.Stderr, "This is a concurrent storage and reading program for trajectory data in XLSX format!")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commandList() {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", cmd.Name, cmd.Desc)
+	}
+	fmt.Fprintln(os.Stderr, "Run \"TrackHelper COMMAND --help\" for the flags of each command.")
+}
+
+func lookupCommand(name string) (command, bool) {
+	for _, cmd := range commandList() {
+		if cmd.Name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	cmd, _ := lookupCommand(name)
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: TrackHelper %s [flags] %s\n", cmd.Name, cmd.Args)
+		fmt.Fprintf(os.Stderr, "%s\n", cmd.Desc)
+		fmt.Fprintln(os.Stderr, "Flags:")
+		fs.PrintDefaults()
+	}
+	fs.String("config", "", "JSON 配置文件，键为本命令的参数名；命令行显式指定的参数优先")
+	fs.Duration("timeout", 0, "整体运行超时，例如 30s、5m；0 表示不限制")
+	return fs
+}
+
+// 按 -timeout 参数为 ctx 设置超时
+func withTimeout(ctx context.Context, fs *flag.FlagSet) (context.Context, context.CancelFunc) {
+	timeout := fs.Lookup("timeout").Value.(flag.Getter).Get().(time.Duration)
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// 将配置文件中的值应用到命令行未显式指定的参数上。
+// 同一配置文件可供多个命令共用，不属于本命令的键被忽略。
+func applyConfigFile(fs *flag.FlagSet, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("解析配置文件失败: %v", err)
+	}
+
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	for name, value := range values {
+		if fs.Lookup(name) == nil || explicit[name] || name == "config" {
+			continue
+		}
+		text := fmt.Sprint(value)
+		if f, ok := value.(float64); ok {
+			// fmt.Sprint 对较大的数使用 1e+06 形式，整数参数无法解析
+			text = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		if err := fs.Set(name, text); err != nil {
+			return &usageError{fmt.Sprintf("配置项 %s 的值无效: %v", name, err)}
+		}
+	}
+	return nil
+}
+
+// 解析子命令参数，位置参数个数不足 minArgs 时返回 usageError
+func parseFlags(fs *flag.FlagSet, args []string, minArgs int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{err.Error()}
+	}
+	// flag 包在第一个位置参数处停止解析，写在位置参数之后的选项会被当作参数；用 "--" 分隔时除外
+	if first := len(args) - fs.NArg(); first == 0 || args[first-1] != "--" {
+		for _, arg := range fs.Args() {
+			if strings.HasPrefix(arg, "-") && arg != "-" {
+				if _, err := strconv.ParseFloat(arg, 64); err != nil {
+					return &usageError{fmt.Sprintf("选项 %s 必须写在位置参数之前", arg)}
+				}
+			}
+		}
+	}
+	if path := fs.Lookup("config").Value.String(); path != "" {
+		if err := applyConfigFile(fs, path); err != nil {
+			return err
+		}
+	}
+	if fs.NArg() < minArgs {
+		fs.Usage()
+		return &usageError{fmt.Sprintf("%s 需要至少 %d 个参数", fs.Name(), minArgs)}
+	}
+	return nil
+}
+
+// 要求路径为已存在的目录
+func requireDir(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("目录 %s 不存在", path)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s 不是目录", path)
+	}
+	return nil
+}
+
+// 解析形如 "(A,B)" 的点参数
+func parsePointArg(arg string) (trackstore.Point, error) {
+	// 去掉多余的空格
+	arg = strings.TrimSpace(arg)
+	// 检查格式是否为 "(A,B)"
+	if !strings.HasPrefix(arg, "(") || !strings.HasSuffix(arg, ")") {
+		return trackstore.Point{}, fmt.Errorf("格式错误，应该是 (A,B) 的形式: %s", arg)
+	}
+	// 去掉括号并分割
+	parts := strings.Split(arg[1:len(arg)-1], ",")
+	if len(parts) != 2 {
+		return trackstore.Point{}, fmt.Errorf("格式错误，应该有两个数值: %s", arg)
+	}
+	// 解析 A 和 B 为 float64
+	A, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return trackstore.Point{}, fmt.Errorf("经度解析失败，应该是浮点数: %s", parts[0])
+	}
+	B, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return trackstore.Point{}, fmt.Errorf("纬度解析失败，应该是浮点数: %s", parts[1])
+	}
+	return trackstore.Point{Longitude: A, Latitude: B}, nil
+}
+
+// 解析多边形参数，以 @ 开头时从文件读取
+func parsePolygonFlag(value string) (trackstore.Polygon, error) {
+	if strings.HasPrefix(value, "@") {
+		data, err := os.ReadFile(value[1:])
+		if err != nil {
+			return trackstore.Polygon{}, fmt.Errorf("读取多边形文件失败: %v", err)
+		}
+		value = string(data)
+	}
+	pg, err := trackstore.ParsePolygon(value)
+	if err != nil {
+		return trackstore.Polygon{}, &usageError{fmt.Sprintf("-polygon 无效: %v", err)}
+	}
+	return pg, nil
+}
+
+// 解析 RFC3339 格式的时间参数，空字符串返回零值
+func parseTimeFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, &usageError{fmt.Sprintf("-%s 应为 RFC3339 格式的时间: %s", name, value)}
+	}
+	return t, nil
+}
+
+const distanceUsage = "距离度量: haversine（球面）、vincenty（WGS84 椭球，精度最高）或 equirectangular（近距离近似，最快）"
+
+func parseMetricFlag(name string) (trackstore.Metric, error) {
+	metric, err := trackstore.ParseMetric(name)
+	if err != nil {
+		return nil, &usageError{fmt.Sprintf("-distance %v", err)}
+	}
+	return metric, nil
+}
+
+const crsUsage = "坐标系: wgs84、gcj02（高德、腾讯）、bd09（百度）、epsg:3857 或 UTM 分带 epsg:326xx/327xx"
+
+func parseCRSFlag(name string) (trackstore.CRS, error) {
+	crs, err := trackstore.ParseCRS(name)
+	if err != nil {
+		return nil, &usageError{fmt.Sprintf("-crs %v", err)}
+	}
+	return crs, nil
+}
+
+// 坐标校验规则，取值为 keep、drop、reject，-swapped 另可取 fix
+func addValidateFlags(fs *flag.FlagSet, opts *trackstore.ValidateOptions) {
+	fs.TextVar(&opts.Invalid, "invalid", opts.Invalid, "NaN 或超出经纬度范围的点: keep、drop 或 reject")
+	fs.TextVar(&opts.Swapped, "swapped", opts.Swapped, "经纬度两列互换时: fix（交换回来）、keep、drop 或 reject")
+	fs.TextVar(&opts.NullIsland, "null-island", opts.NullIsland, "坐标为 (0, 0) 的点: keep、drop 或 reject")
+	fs.TextVar(&opts.Duplicate, "duplicate", opts.Duplicate, "与前一个点坐标、时间都相同的点: keep、drop 或 reject")
+}
+
+// 修正异常点的方式与卡尔曼平滑的参数
+func addCleanFlags(fs *flag.FlagSet, opts *trackstore.Options) {
+	fs.TextVar(&opts.Clean, "clean", opts.Clean, "修正异常点的方式: interpolate（在前后正常点之间插值）或 kalman（按定位精度加权的卡尔曼平滑）")
+	fs.Float64Var(&opts.Kalman.MaxError, "max-error", 0, "-clean kalman 时拒绝水平误差（米）超过该值的定位，0 表示不按精度拒绝")
+	fs.Float64Var(&opts.Kalman.UERE, "uere", trackstore.DefaultUERE, "只记录了 HDOP 的点按 HDOP 乘以该值（米）估计水平误差，-weight-hdop 与 -clean kalman 共用")
+}
+
+func checkCleanFlags(opts trackstore.Options) error {
+	if opts.Kalman.MaxError < 0 || opts.Kalman.UERE <= 0 {
+		return &usageError{"-max-error 不能为负数，-uere 必须大于 0"}
+	}
+	if opts.Clean != trackstore.CleanKalman && opts.Kalman.MaxError > 0 {
+		return &usageError{"-max-error 只适用于 -clean kalman"}
+	}
+	return nil
+}
+
+func checkValidateFlags(opts trackstore.ValidateOptions) error {
+	if err := opts.Check(); err != nil {
+		return &usageError{err.Error()}
+	}
+	return nil
+}
+
+const projectionUsage = "绘图投影: webmercator、equirectangular、utm（自动分带）、utm:<带号><n|s> 或 enu；后三者以数据范围的中心为参考点"
+
+// 检查投影名称，参考点在绘图时才确定
+func parseProjectionFlag(name string) (string, error) {
+	if _, err := trackstore.NewProjection(name, trackstore.Point{}); err != nil {
+		return "", &usageError{fmt.Sprintf("-projection %v", err)}
+	}
+	return name, nil
+}
+
+// 绘图命令共用的图片参数
+type plotFlags struct {
+	format        *string
+	title         *string
+	width, height *float64
+}
+
+func addPlotFlags(fs *flag.FlagSet, title string) *plotFlags {
+	return &plotFlags{
+		format: fs.String("format", "", "图片格式，覆盖 -out 的扩展名: png、jpg、tif、svg、pdf 或 eps"),
+		title:  fs.String("title", title, "图片标题，默认字体不含中文字形"),
+		width:  fs.Float64("width", 4, "图片宽度（英寸）"),
+		height: fs.Float64("height", 4, "图片高度（英寸）"),
+	}
+}
+
+// 检查尺寸与输出格式，未指定 -format 时按 out 的扩展名确定格式
+func (pf *plotFlags) options(out string) (plotOptions, error) {
+	opts, ext, err := pf.parse(out)
+	if err != nil {
+		return plotOptions{}, err
+	}
+	switch ext {
+	case "png", "jpg", "jpeg", "tif", "tiff", "svg", "pdf", "eps":
+		return opts, nil
+	}
+	return plotOptions{}, &usageError{fmt.Sprintf("不支持的图片格式 %q，可选 png、jpg、tif、svg、pdf 或 eps", ext)}
+}
+
+// 与 options 相同，用于 animate：格式为 gif 时输出 GIF 动画，否则作为目录写入 PNG 帧
+func (pf *plotFlags) animateOptions(out string) (plotOptions, error) {
+	opts, ext, err := pf.parse(out)
+	if err != nil {
+		return plotOptions{}, err
+	}
+	if opts.Format != "" && opts.Format != "gif" && opts.Format != "png" {
+		return plotOptions{}, &usageError{fmt.Sprintf("不支持的动画格式 %q，可选 gif 或 png", opts.Format)}
+	}
+	opts.Format = "png"
+	if ext == "gif" {
+		opts.Format = "gif"
+	}
+	return opts, nil
+}
+
+// 检查尺寸，返回 -format 或 out 的扩展名（小写，不含点）
+func (pf *plotFlags) parse(out string) (plotOptions, string, error) {
+	if *pf.width <= 0 || *pf.height <= 0 {
+		return plotOptions{}, "", &usageError{"图片宽度和高度必须大于 0"}
+	}
+	opts := plotOptions{Title: *pf.title, Width: vg.Length(*pf.width) * vg.Inch, Height: vg.Length(*pf.height) * vg.Inch}
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(out)), ".")
+	if *pf.format != "" {
+		opts.Format = strings.ToLower(*pf.format)
+		ext = opts.Format
+	}
+	return opts, ext, nil
+}
+
+func cmdStore(ctx context.Context, args []string) error {
+	fs := newFlagSet("store")
+	opts := trackstore.DefaultOptions()
+	fs.Float64Var(&opts.MaxLon, "max-lon", opts.MaxLon, "单个数据块的经度跨度阈值")
+	fs.Float64Var(&opts.MaxLat, "max-lat", opts.MaxLat, "单个数据块的纬度跨度阈值")
+	fs.IntVar(&opts.Overlap, "overlap", opts.Overlap, "相邻数据块之间重叠的点数")
+	fs.IntVar(&opts.CleanWorkers, "clean-workers", opts.CleanWorkers, "清洗数据的 goroutine 数量")
+	fs.IntVar(&opts.WriteWorkers, "write-workers", opts.WriteWorkers, "写入数据块的 goroutine 数量")
+	fs.BoolVar(&opts.AutoTune, "autotune", opts.AutoTune, "根据队列积压自动扩容：清洗最多 GOMAXPROCS 个，写入最多 4*GOMAXPROCS 个")
+	distance := fs.String("distance", "haversine", "检测速度突变使用的"+distanceUsage)
+	fs.Float64Var(&opts.MaxClimbRate, "max-climb", 0, "大于 0 时将升降速度（米/秒）超过该值且立即反向的点视为高程尖刺并修正")
+	fs.BoolVar(&opts.WeightHDOP, "weight-hdop", false, "按 HDOP 收紧速度突变阈值，HDOP 越大的点越容易被判为异常")
+	crs := fs.String("crs", "wgs84", "SOURCE 中坐标的"+crsUsage+"；写入前统一转换为 WGS84")
+	addCleanFlags(fs, &opts)
+	addValidateFlags(fs, &opts.Validate)
+	trajectory := fs.String("trajectory", "", "轨迹名称，默认为 SOURCE 的文件名（不含扩展名）")
+	tracePath := fs.String("trace", "", "将运行轨迹写入该文件，可用 \"go tool trace\" 查看")
+	if err := parseFlags(fs, args, 2); err != nil {
+		return err
+	}
+	var err error
+	if opts.Distance, err = parseMetricFlag(*distance); err != nil {
+		return err
+	}
+	if opts.InputCRS, err = parseCRSFlag(*crs); err != nil {
+		return err
+	}
+	if err := checkValidateFlags(opts.Validate); err != nil {
+		return err
+	}
+	if err := checkCleanFlags(opts); err != nil {
+		return err
+	}
+
+	source, dest := fs.Arg(0), fs.Arg(1)
+	if *trajectory == "" {
+		*trajectory = strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+	}
+	if info, err := os.Stat(source); err != nil || info.IsDir() {
+		return fmt.Errorf("文件 %s 不存在", source)
+	}
+	if opts.CleanWorkers < 1 || opts.WriteWorkers < 1 {
+		return &usageError{"goroutine 数量必须大于 0"}
+	}
+	if opts.Overlap < 0 || !(opts.MaxLon > 0) || !(opts.MaxLat > 0) {
+		return &usageError{"-overlap 不能为负数，-max-lon 与 -max-lat 必须大于 0"}
+	}
+
+	stop, err := traceGO(*tracePath)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	ctx, cancel := withTimeout(ctx, fs)
+	defer cancel()
+	return execSTORE(ctx, source, dest, *trajectory, opts)
+}
+
+func cmdRead(ctx context.Context, args []string) error {
+	fs := newFlagSet("read")
+	out := fs.String("out", "trajectory.png", "轨迹图输出路径，按扩展名确定格式: png、jpg、tif、svg、pdf 或 eps")
+	pf := addPlotFlags(fs, "Trajectories")
+	projection := fs.String("projection", "webmercator", projectionUsage)
+	workers := fs.Int("workers", trackstore.DefaultWorkers(), "查询数据块的 goroutine 数量")
+	var opts readOptions
+	fs.Float64Var(&opts.Radius, "radius", 0, "查询每个 POINT 周围该半径（米）内的全部点")
+	fs.IntVar(&opts.K, "k", 0, "输出并绘制距离每个 POINT 最近的 K 个点")
+	distance := fs.String("distance", "haversine", "-radius 与 -k 使用的"+distanceUsage)
+	crs := fs.String("crs", "wgs84", "POINT 与 -k 输出坐标的"+crsUsage+"；-polygon 始终为 WGS84")
+	fs.StringVar(&opts.Profile, "profile", "", "同时将查询到的轨迹的高程剖面图（海拔-累计距离）保存到该路径，格式同 -out")
+	fs.BoolVar(&opts.Compare, "compare", false, "用不同颜色叠加绘制原始轨迹与清洗后的轨迹，并标出被修正的异常点")
+	polygon := fs.String("polygon", "", "只查询多边形内的点，WKT 或 GeoJSON 格式，以 @ 开头表示从文件读取；不带 -radius 时不需要 POINT")
+	tracePath := fs.String("trace", "", "将运行轨迹写入该文件，可用 \"go tool trace\" 查看")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	if *polygon != "" {
+		pg, err := parsePolygonFlag(*polygon)
+		if err != nil {
+			return err
+		}
+		opts.Polygon = &pg
+	}
+	if opts.K < 0 {
+		return &usageError{"-k 不能为负数"}
+	}
+	if opts.K > 0 && (opts.Radius > 0 || opts.Polygon != nil) {
+		return &usageError{"-k 不能与 -radius、-polygon 同时使用"}
+	}
+	if fs.NArg() < 2 && (opts.Polygon == nil || opts.Radius > 0) {
+		fs.Usage()
+		return &usageError{"read 需要至少一个 POINT"}
+	}
+
+	directory := fs.Arg(0)
+	if err := requireDir(directory); err != nil {
+		return err
+	}
+	if *workers < 1 {
+		return &usageError{"goroutine 数量必须大于 0"}
+	}
+	plotOpts, err := pf.options(*out)
+	if err != nil {
+		return err
+	}
+	if opts.Profile != "" {
+		if _, err := pf.options(opts.Profile); err != nil {
+			return err
+		}
+	}
+	if plotOpts.Projection, err = parseProjectionFlag(*projection); err != nil {
+		return err
+	}
+	if opts.Distance, err = parseMetricFlag(*distance); err != nil {
+		return err
+	}
+	if opts.CRS, err = parseCRSFlag(*crs); err != nil {
+		return err
+	}
+
+	var points []trackstore.Point
+	for i, arg := range fs.Args()[1:] {
+		pt, err := parsePointArg(arg)
+		if err != nil {
+			return &usageError{fmt.Sprintf("参数 #%d %v", i+1, err)}
+		}
+		pt.Longitude, pt.Latitude = opts.CRS.ToWGS84(pt.Longitude, pt.Latitude)
+		points = append(points, pt)
+	}
+
+	stop, err := traceGO(*tracePath)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	ctx, cancel := withTimeout(ctx, fs)
+	defer cancel()
+	return execREAD(ctx, points, directory, *out, *workers, opts, plotOpts)
+}
+
+func cmdExport(ctx context.Context, args []string) error {
+	fs := newFlagSet("export")
+	out := fs.String("out", "-", "输出文件路径，\"-\" 表示标准输出")
+	format := fs.String("format", "csv", "输出格式: csv 或 json")
+	crs := fs.String("crs", "wgs84", "输出的"+crsUsage+"；投影坐标系输出 x、y 列（米）")
+	var q trackstore.Query
+	fs.StringVar(&q.Trajectory, "trajectory", "", "只导出该轨迹")
+	since := fs.String("since", "", "只导出该时间（RFC3339）及之后的点")
+	until := fs.String("until", "", "只导出该时间（RFC3339）之前的点")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	var err error
+	if q.Since, err = parseTimeFlag("since", *since); err != nil {
+		return err
+	}
+	if q.Until, err = parseTimeFlag("until", *until); err != nil {
+		return err
+	}
+
+	directory := fs.Arg(0)
+	if err := requireDir(directory); err != nil {
+		return err
+	}
+	if *format != "csv" && *format != "json" {
+		return &usageError{fmt.Sprintf("不支持的输出格式: %s", *format)}
+	}
+	outCRS, err := parseCRSFlag(*crs)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, fs)
+	defer cancel()
+	return execEXPORT(ctx, directory, *out, *format, q, outCRS)
+}
+
+func cmdHeatmap(ctx context.Context, args []string) error {
+	fs := newFlagSet("heatmap")
+	out := fs.String("out", "heatmap.png", "热力图输出路径，按扩展名确定格式: png、jpg、tif、svg、pdf 或 eps")
+	pf := addPlotFlags(fs, "Heatmap")
+	gridPath := fs.String("grid", "", "同时导出格网，扩展名为 .csv 时导出 CSV，否则导出 ESRI ASCII 栅格（EPSG:3857）")
+	opts := trackstore.DefaultHeatmapOptions()
+	fs.IntVar(&opts.Cols, "cols", opts.Cols, "格网列数")
+	fs.IntVar(&opts.Rows, "rows", 0, "格网行数，0 表示按范围的宽高比确定")
+	fs.BoolVar(&opts.Dwell, "dwell", false, "按停留时间（秒）加权，而不是按点数")
+	fs.DurationVar(&opts.MaxDwell, "max-dwell", opts.MaxDwell, "单个点停留时间的上限，0 表示不限制")
+	var q trackstore.Query
+	bbox := fs.String("bbox", "", "格网范围: 最小经度,最小纬度,最大经度,最大纬度；为空时取全部数据的范围")
+	fs.StringVar(&q.Trajectory, "trajectory", "", "只统计该轨迹")
+	since := fs.String("since", "", "只统计该时间（RFC3339）及之后的点")
+	until := fs.String("until", "", "只统计该时间（RFC3339）之前的点")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	var err error
+	if q.Since, err = parseTimeFlag("since", *since); err != nil {
+		return err
+	}
+	if q.Until, err = parseTimeFlag("until", *until); err != nil {
+		return err
+	}
+	if *bbox != "" {
+		b, err := parseFloats(*bbox, 4)
+		if err != nil {
+			return &usageError{fmt.Sprintf("-bbox %v", err)}
+		}
+		if b[0] >= b[2] || b[1] >= b[3] {
+			return &usageError{"-bbox 的最小值必须小于最大值"}
+		}
+		q.BBox = &trackstore.BBox{
+			Min: trackstore.Point{Longitude: b[0], Latitude: b[1]},
+			Max: trackstore.Point{Longitude: b[2], Latitude: b[3]},
+		}
+	}
+	if opts.Cols < 1 || opts.Rows < 0 {
+		return &usageError{"-cols 必须大于 0，-rows 不能为负数"}
+	}
+	plotOpts, err := pf.options(*out)
+	if err != nil {
+		return err
+	}
+
+	directory := fs.Arg(0)
+	if err := requireDir(directory); err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, fs)
+	defer cancel()
+	return execHEATMAP(ctx, directory, *out, *gridPath, q, opts, plotOpts)
+}
+
+func cmdAnimate(ctx context.Context, args []string) error {
+	fs := newFlagSet("animate")
+	out := fs.String("out", "trajectory.gif", "输出路径，扩展名为 .gif 时输出 GIF 动画，否则作为目录写入编号的 PNG 帧")
+	pf := addPlotFlags(fs, "Playback")
+	fs.Lookup("format").Usage = "输出格式，覆盖 -out 的扩展名: gif（GIF 动画）或 png（PNG 帧目录）"
+	projection := fs.String("projection", "webmercator", projectionUsage)
+	var opts animateOptions
+	fs.IntVar(&opts.Frames, "frames", 100, "总帧数")
+	fs.IntVar(&opts.FPS, "fps", 10, "每秒帧数")
+	fs.DurationVar(&opts.Tail, "tail", 0, "只显示最近这段时间内的轨迹，例如 5m；0 表示显示全部已走过的轨迹")
+	fs.BoolVar(&opts.Timestamp, "timestamp", true, "在每帧标题中显示当前时间")
+	var q trackstore.Query
+	fs.StringVar(&q.Trajectory, "trajectory", "", "回放的轨迹，必须指定")
+	since := fs.String("since", "", "从该时间（RFC3339）开始回放")
+	until := fs.String("until", "", "回放到该时间（RFC3339）为止")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	var err error
+	if q.Since, err = parseTimeFlag("since", *since); err != nil {
+		return err
+	}
+	if q.Until, err = parseTimeFlag("until", *until); err != nil {
+		return err
+	}
+	if q.Trajectory == "" {
+		return &usageError{"animate 需要 -trajectory"}
+	}
+	if opts.Frames < 1 || opts.FPS < 1 || opts.Tail < 0 {
+		return &usageError{"-frames、-fps 必须大于 0，-tail 不能为负数"}
+	}
+	plotOpts, err := pf.animateOptions(*out)
+	if err != nil {
+		return err
+	}
+	if plotOpts.Projection, err = parseProjectionFlag(*projection); err != nil {
+		return err
+	}
+
+	directory := fs.Arg(0)
+	if err := requireDir(directory); err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, fs)
+	defer cancel()
+	return execANIMATE(ctx, directory, *out, q, opts, plotOpts)
+}
+
+func cmdServe(ctx context.Context, args []string) error {
+	fs := newFlagSet("serve")
+	addr := fs.String("addr", "localhost:8080", "监听地址")
+	grpcAddr := fs.String("grpc", "", "同时在该地址提供 gRPC 写入与查询服务，为空时不启动")
+	opts := trackstore.DefaultOptions()
+	fs.IntVar(&opts.QueryWorkers, "workers", opts.QueryWorkers, "每个查询并发读取数据块的 goroutine 数量")
+	fs.IntVar(&opts.CacheChunks, "cache", 256, "缓存已解码数据块的个数，0 表示不缓存")
+	maxPoints := fs.Int("max-points", 100000, "单次查询最多返回的点数")
+	maxIngest := fs.Int("max-ingest", trackrpc.DefaultMaxIngestPoints, "单次 gRPC 写入最多接收的点数，超过时返回 RESOURCE_EXHAUSTED")
+	distance := fs.String("distance", "haversine", "圆形查询与 gRPC 写入清洗使用的"+distanceUsage)
+	fs.Float64Var(&opts.MaxClimbRate, "max-climb", 0, "gRPC 写入与清洗预览检测高程尖刺的升降速度阈值（米/秒），0 表示不检测")
+	fs.BoolVar(&opts.WeightHDOP, "weight-hdop", false, "gRPC 写入与清洗预览按 HDOP 收紧速度突变阈值")
+	crs := fs.String("crs", "wgs84", "gRPC 写入与清洗预览上传坐标的"+crsUsage+"；查询结果始终为 WGS84")
+	addCleanFlags(fs, &opts)
+	addValidateFlags(fs, &opts.Validate)
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	var err error
+	if opts.Distance, err = parseMetricFlag(*distance); err != nil {
+		return err
+	}
+	if opts.InputCRS, err = parseCRSFlag(*crs); err != nil {
+		return err
+	}
+	if err := checkValidateFlags(opts.Validate); err != nil {
+		return err
+	}
+	if err := checkCleanFlags(opts); err != nil {
+		return err
+	}
+
+	directory := fs.Arg(0)
+	if err := requireDir(directory); err != nil {
+		return err
+	}
+	if opts.QueryWorkers < 1 {
+		return &usageError{"goroutine 数量必须大于 0"}
+	}
+	if opts.CacheChunks < 0 || *maxPoints < 1 || *maxIngest < 1 {
+		return &usageError{"-cache 不能为负数，-max-points 与 -max-ingest 必须大于 0"}
+	}
+
+	ctx, cancel := withTimeout(ctx, fs)
+	defer cancel()
+	return execSERVE(ctx, directory, *addr, *grpcAddr, opts, *maxPoints, *maxIngest)
+}
+
+func cmdReindex(ctx context.Context, args []string) error {
+	fs := newFlagSet("reindex")
+	workers := fs.Int("workers", trackstore.DefaultWorkers(), "读取数据块的 goroutine 数量")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+
+	directory := fs.Arg(0)
+	if err := requireDir(directory); err != nil {
+		return err
+	}
+	if *workers < 1 {
+		return &usageError{"goroutine 数量必须大于 0"}
+	}
+
+	ctx, cancel := withTimeout(ctx, fs)
+	defer cancel()
+	return trackstore.Reindex(ctx, directory, *workers)
+}
+
+func cmdMigrate(ctx context.Context, args []string) error {
+	fs := newFlagSet("migrate")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+
+	directory := fs.Arg(0)
+	if err := requireDir(directory); err != nil {
+		return err
+	}
+
+	ctx, cancel := withTimeout(ctx, fs)
+	defer cancel()
+	return trackstore.Migrate(ctx, directory)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFlagsTrailingOptions(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		ok   bool
+	}{
+		{[]string{"-overlap", "3", "a.xlsx", "out"}, true},
+		{[]string{"a.xlsx", "out", "-overlap", "3"}, false},
+		{[]string{"--", "a.xlsx", "-out"}, true},
+		{[]string{"a.xlsx", "-", "-1.5"}, true},
+	} {
+		fs := newFlagSet("store")
+		fs.Int("overlap", 2, "")
+		err := parseFlags(fs, tc.args, 2)
+		var uerr *usageError
+		if tc.ok && err != nil {
+			t.Errorf("%v: 不应报错: %v", tc.args, err)
+		}
+		if !tc.ok && !errors.As(err, &uerr) {
+			t.Errorf("%v: 位置参数之后的选项应返回 usageError，实际 %v", tc.args, err)
+		}
+	}
+}
+
+func TestApplyConfigFileNumbers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	os.WriteFile(path, []byte(`{"cache": 1000000, "max-lon": 0.0005}`), 0o644)
+
+	fs := newFlagSet("serve")
+	cache := fs.Int("cache", 0, "")
+	maxLon := fs.Float64("max-lon", 0, "")
+	if err := applyConfigFile(fs, path); err != nil {
+		t.Fatalf("应用配置文件失败: %v", err)
+	}
+	if *cache != 1000000 || *maxLon != 0.0005 {
+		t.Errorf("配置值不正确: %d %v", *cache, *maxLon)
+	}
+}
+
+func TestAnimateOptions(t *testing.T) {
+	for _, tc := range []struct {
+		args   []string
+		out    string
+		format string // 为空表示应返回 usageError
+	}{
+		{nil, "track.gif", "gif"},
+		{nil, "frames", "png"},
+		{[]string{"-format", "gif"}, "frames", "gif"},
+		{[]string{"-format", "PNG"}, "track.gif", "png"},
+		{[]string{"-format", "svg"}, "track.gif", ""},
+		{[]string{"-width", "0"}, "track.gif", ""},
+	} {
+		fs := newFlagSet("animate")
+		pf := addPlotFlags(fs, "Playback")
+		if err := fs.Parse(tc.args); err != nil {
+			t.Fatal(err)
+		}
+		opts, err := pf.animateOptions(tc.out)
+		var uerr *usageError
+		if tc.format == "" {
+			if !errors.As(err, &uerr) {
+				t.Errorf("%v %s: 应返回 usageError，实际 %v", tc.args, tc.out, err)
+			}
+			continue
+		}
+		if err != nil || opts.Format != tc.format || opts.Title != "Playback" {
+			t.Errorf("%v %s: 期望格式 %s，实际 %+v %v", tc.args, tc.out, tc.format, opts, err)
+		}
+	}
+}
+
+func TestStoreSplitFlags(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "a.xlsx")
+	os.WriteFile(source, nil, 0o644)
+	for _, args := range [][]string{
+		{"-overlap", "-1"},
+		{"-max-lon", "0"},
+		{"-max-lat", "-0.001"},
+	} {
+		err := cmdStore(context.Background(), append(args, source, t.TempDir()))
+		var uerr *usageError
+		if !errors.As(err, &uerr) {
+			t.Errorf("%v: 应返回 usageError，实际 %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+
+	"os_project/trackstore"
+)
+
+// 对比图中各图层的样式
+var (
+	rawLineStyle        = draw.LineStyle{Color: color.RGBA{R: 150, G: 150, B: 150, A: 255}, Width: vg.Points(1)}
+	cleanedLineStyle    = draw.LineStyle{Color: color.RGBA{R: 21, G: 101, B: 192, A: 255}, Width: vg.Points(1.5)}
+	outlierGlyphStyle   = draw.GlyphStyle{Color: color.RGBA{R: 211, G: 47, B: 47, A: 255}, Radius: vg.Points(3), Shape: draw.CircleGlyph{}}
+	correctionLineStyle = draw.LineStyle{Color: color.RGBA{R: 211, G: 47, B: 47, A: 255}, Width: vg.Points(0.5), Dashes: []vg.Length{vg.Points(2), vg.Points(2)}}
+)
+
+// 一段轨迹的原始坐标与清洗后坐标，outliers 为被修正的点的下标。
+// 没有被修正的点原始坐标与清洗后相同
+func comparisonXYs(pts []trackstore.Point, proj trackstore.Projection) (raw, cleaned plotter.XYs, outliers []int) {
+	raw = make(plotter.XYs, len(pts))
+	cleaned = make(plotter.XYs, len(pts))
+	for i, pt := range pts {
+		cleaned[i].X, cleaned[i].Y = plotXY(proj, pt)
+		raw[i] = cleaned[i]
+		if pt.Original == nil {
+			continue
+		}
+		raw[i].X, raw[i].Y = plotXY(proj, *pt.Original)
+		outliers = append(outliers, i)
+	}
+	return raw, cleaned, outliers
+}
+
+// 叠加绘制一段轨迹的原始轨迹（灰）与清洗后的轨迹（蓝），
+// 被修正的点在原始位置画红色圆点，并用虚线连到修正后的位置
+func plotComparison(pts []trackstore.Point, plt *plot.Plot, proj trackstore.Projection) error {
+	if len(pts) == 0 {
+		return nil
+	}
+
+	raw, cleaned, indices := comparisonXYs(pts, proj)
+	var outliers plotter.XYs
+	var corrections []plot.Plotter
+	for _, i := range indices {
+		outliers = append(outliers, raw[i])
+		line, err := plotter.NewLine(plotter.XYs{raw[i], cleaned[i]})
+		if err != nil {
+			return fmt.Errorf("创建连线失败: %v", err)
+		}
+		line.LineStyle = correctionLineStyle
+		corrections = append(corrections, line)
+	}
+
+	rawLine, err := plotter.NewLine(raw)
+	if err != nil {
+		return fmt.Errorf("创建折线失败: %v", err)
+	}
+	rawLine.LineStyle = rawLineStyle
+	cleanedLine, err := plotter.NewLine(cleaned)
+	if err != nil {
+		return fmt.Errorf("创建折线失败: %v", err)
+	}
+	cleanedLine.LineStyle = cleanedLineStyle
+
+	plotters := append([]plot.Plotter{rawLine, cleanedLine}, corrections...)
+	if len(outliers) > 0 {
+		scatter, err := plotter.NewScatter(outliers)
+		if err != nil {
+			return fmt.Errorf("创建散点图失败: %v", err)
+		}
+		scatter.GlyphStyle = outlierGlyphStyle
+		plotters = append(plotters, scatter)
+	}
+
+	plt.Add(plotters...)
+	return nil
+}
+
+// 各段轨迹分别绘制，图例只在最后统一添加一次。
+// 默认字体不含中文字形，图例使用英文
+func addComparisonLegend(plt *plot.Plot) {
+	plt.Legend.Add("raw", &plotter.Line{LineStyle: rawLineStyle})
+	plt.Legend.Add("cleaned", &plotter.Line{LineStyle: cleanedLineStyle})
+	plt.Legend.Add("outlier", &plotter.Scatter{GlyphStyle: outlierGlyphStyle})
+	plt.Legend.Add("correction", &plotter.Line{LineStyle: correctionLineStyle})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+
+	"os_project/trackstore"
+)
+
+// 经纬度直接作为平面坐标，便于核对结果
+type identityProjection struct{}
+
+func (identityProjection) Forward(lon, lat float64) (x, y float64) { return lon, lat }
+func (identityProjection) Inverse(x, y float64) (lon, lat float64) { return x, y }
+func (identityProjection) Name() string                            { return "identity" }
+
+func TestComparisonXYs(t *testing.T) {
+	// 第 i 个点位于 (i, 0)，moved 中的点原始位置偏到 (i, 1)
+	track := func(n int, moved ...int) []trackstore.Point {
+		pts := make([]trackstore.Point, n)
+		for i := range pts {
+			pts[i] = trackstore.Point{Longitude: float64(i)}
+			if slices.Contains(moved, i) {
+				pts[i].Flag = trackstore.FlagInterpolated
+				pts[i].Original = &trackstore.Point{Longitude: float64(i), Latitude: 1}
+			}
+		}
+		return pts
+	}
+
+	for _, tc := range []struct {
+		name     string
+		pts      []trackstore.Point
+		outliers []int
+	}{
+		{"空轨迹", nil, nil},
+		{"没有修正", track(4), nil},
+		{"中间一点", track(5, 2), []int{2}},
+		{"首尾两点", track(5, 0, 4), []int{0, 4}},
+		{"相邻两点", track(6, 2, 3), []int{2, 3}},
+	} {
+		raw, cleaned, outliers := comparisonXYs(tc.pts, identityProjection{})
+		if len(raw) != len(tc.pts) || len(cleaned) != len(tc.pts) {
+			t.Errorf("%s: 点数应为 %d，实际 %d %d", tc.name, len(tc.pts), len(raw), len(cleaned))
+			continue
+		}
+		if !slices.Equal(outliers, tc.outliers) {
+			t.Errorf("%s: 修正点应为 %v，实际 %v", tc.name, tc.outliers, outliers)
+		}
+		for i := range tc.pts {
+			wantRaw := plotter.XY{X: float64(i)}
+			if slices.Contains(tc.outliers, i) {
+				wantRaw.Y = 1
+			}
+			if raw[i] != wantRaw || cleaned[i] != (plotter.XY{X: float64(i)}) {
+				t.Errorf("%s: 第 %d 个点应为 %v -> (%d, 0)，实际 %v -> %v", tc.name, i, wantRaw, i, raw[i], cleaned[i])
+			}
+		}
+	}
+}
+
+func TestPlotComparisonRange(t *testing.T) {
+	pts := []trackstore.Point{
+		{Longitude: 0},
+		{Longitude: 1, Original: &trackstore.Point{Longitude: 1, Latitude: 5}},
+		{Longitude: 2},
+	}
+	plt := plot.New()
+	if err := plotComparison(pts, plt, identityProjection{}); err != nil {
+		t.Fatalf("绘制失败: %v", err)
+	}
+	// 坐标轴范围应包含修正前的原始位置
+	if plt.X.Min != 0 || plt.X.Max != 2 || plt.Y.Min != 0 || plt.Y.Max != 5 {
+		t.Errorf("坐标轴范围不正确: x [%v, %v] y [%v, %v]", plt.X.Min, plt.X.Max, plt.Y.Min, plt.Y.Max)
+	}
+	if err := plotComparison(nil, plot.New(), identityProjection{}); err != nil {
+		t.Errorf("空轨迹不应报错: %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"os_project/trackstore"
+)
+
+// 导出记录：所属轨迹、数据块与点坐标，没有定位时间、海拔、附加属性的点省略对应字段
+type exportRecord struct {
+	Trajectory string       `json:"trajectory"`
+	TaskIdx    int          `json:"task"`
+	Longitude  float64      `json:"lon"`
+	Latitude   float64      `json:"lat"`
+	Time       *time.Time   `json:"time,omitempty"`
+	Altitude   *float64     `json:"alt,omitempty"`
+	Attrs      *exportAttrs `json:"attrs,omitempty"`
+}
+
+// 投影坐标系下的导出记录，坐标单位为米
+type exportXYRecord struct {
+	Trajectory string       `json:"trajectory"`
+	TaskIdx    int          `json:"task"`
+	X          float64      `json:"x"`
+	Y          float64      `json:"y"`
+	Time       *time.Time   `json:"time,omitempty"`
+	Altitude   *float64     `json:"alt,omitempty"`
+	Attrs      *exportAttrs `json:"attrs,omitempty"`
+}
+
+// 附加属性，只输出记录了的字段
+type exportAttrs struct {
+	Speed      *float64          `json:"speed,omitempty"`
+	Heading    *float64          `json:"heading,omitempty"`
+	HDOP       *float64          `json:"hdop,omitempty"`
+	Accuracy   *float64          `json:"accuracy,omitempty"`
+	Satellites *int              `json:"sats,omitempty"`
+	Extra      map[string]string `json:"extra,omitempty"`
+}
+
+func newExportAttrs(a *trackstore.Attributes) *exportAttrs {
+	if a == nil {
+		return nil
+	}
+	e := &exportAttrs{Extra: a.Extra}
+	if a.Has(trackstore.AttrSpeed) {
+		e.Speed = &a.Speed
+	}
+	if a.Has(trackstore.AttrHeading) {
+		e.Heading = &a.Heading
+	}
+	if a.Has(trackstore.AttrHDOP) {
+		e.HDOP = &a.HDOP
+	}
+	if a.Has(trackstore.AttrAccuracy) {
+		e.Accuracy = &a.Accuracy
+	}
+	if a.Has(trackstore.AttrSatellites) {
+		e.Satellites = &a.Satellites
+	}
+	return e
+}
+
+// CSV 中附加属性的列：速度、航向、HDOP、水平误差、卫星数，其他列合并为一列 "列名=值"，以分号分隔
+func attrColumns(a *trackstore.Attributes) []string {
+	cols := make([]string, 6)
+	if a.Has(trackstore.AttrSpeed) {
+		cols[0] = strconv.FormatFloat(a.Speed, 'f', -1, 64)
+	}
+	if a.Has(trackstore.AttrHeading) {
+		cols[1] = strconv.FormatFloat(a.Heading, 'f', -1, 64)
+	}
+	if a.Has(trackstore.AttrHDOP) {
+		cols[2] = strconv.FormatFloat(a.HDOP, 'f', -1, 64)
+	}
+	if a.Has(trackstore.AttrAccuracy) {
+		cols[3] = strconv.FormatFloat(a.Accuracy, 'f', -1, 64)
+	}
+	if a.Has(trackstore.AttrSatellites) {
+		cols[4] = strconv.Itoa(a.Satellites)
+	}
+	if a != nil && len(a.Extra) > 0 {
+		pairs := make([]string, 0, len(a.Extra))
+		for _, k := range slices.Sorted(maps.Keys(a.Extra)) {
+			pairs = append(pairs, k+"="+a.Extra[k])
+		}
+		cols[5] = strings.Join(pairs, ";")
+	}
+	return cols
+}
+
+// 按轨迹、数据块顺序导出满足查询条件的轨迹点，坐标转换到 crs
+func execEXPORT(ctx context.Context, directory, outPath, format string, q trackstore.Query, crs trackstore.CRS) error {
+	opts := trackstore.DefaultOptions()
+	store, err := trackstore.Open(directory, opts)
+	if err != nil {
+		return fmt.Errorf("读取索引表失败: %v", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if outPath != "-" {
+		file, err := os.Create(outPath)
+		if err != nil {
+			return fmt.Errorf("创建文件失败: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	w := bufio.NewWriter(out)
+
+	records := []any{}
+	cw := csv.NewWriter(w)
+	if format == "csv" {
+		if crs.Geographic() {
+			cw.Write([]string{"trajectory", "task", "lon", "lat", "time", "alt", "speed", "heading", "hdop", "accuracy", "sats", "extra"})
+		} else {
+			cw.Write([]string{"trajectory", "task", "x", "y", "time", "alt", "speed", "heading", "hdop", "accuracy", "sats", "extra"})
+		}
+	}
+	for chunk, err := range store.QueryChunks(ctx, q) {
+		if err != nil {
+			return err
+		}
+		for _, p := range chunk.Points {
+			x, y := crs.FromWGS84(p.Longitude, p.Latitude)
+			if format == "json" {
+				var ts *time.Time
+				if !p.Time.IsZero() {
+					ts = &p.Time
+				}
+				var alt *float64
+				if p.HasAltitude {
+					alt = &p.Altitude
+				}
+				if crs.Geographic() {
+					records = append(records, exportRecord{chunk.Meta.Trajectory, chunk.Meta.TaskIdx, x, y, ts, alt, newExportAttrs(p.Attrs)})
+				} else {
+					records = append(records, exportXYRecord{chunk.Meta.Trajectory, chunk.Meta.TaskIdx, x, y, ts, alt, newExportAttrs(p.Attrs)})
+				}
+				continue
+			}
+			var ts, alt string
+			if !p.Time.IsZero() {
+				ts = p.Time.Format(time.RFC3339)
+			}
+			if p.HasAltitude {
+				alt = strconv.FormatFloat(p.Altitude, 'f', -1, 64)
+			}
+			cw.Write(append([]string{
+				chunk.Meta.Trajectory,
+				strconv.Itoa(chunk.Meta.TaskIdx),
+				strconv.FormatFloat(x, 'f', -1, 64),
+				strconv.FormatFloat(y, 'f', -1, 64),
+				ts,
+				alt,
+			}, attrColumns(p.Attrs)...))
+		}
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(records); err != nil {
+			return fmt.Errorf("写入 JSON 失败: %v", err)
+		}
+	} else {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("写入 CSV 失败: %v", err)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"image/color"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/palette/moreland"
+	"gonum.org/v1/plot/plotter"
+
+	"os_project/trackstore"
+)
+
+// 将格网适配为 plotter.GridXYZ：行号自南向北增大，坐标为 EPSG:3857（米），
+// 没有点的单元为 NaN，绘制为透明
+type heatmapGrid struct {
+	h *trackstore.Heatmap
+}
+
+func (g heatmapGrid) Dims() (c, r int) { return g.h.Cols, g.h.Rows }
+
+func (g heatmapGrid) Z(c, r int) float64 {
+	v := g.h.Value(c, g.h.Rows-1-r)
+	if v == 0 {
+		return math.NaN()
+	}
+	return v
+}
+
+func (g heatmapGrid) X(c int) float64 {
+	dx, _ := g.h.CellSize()
+	return g.h.MinX + (float64(c)+0.5)*dx
+}
+
+func (g heatmapGrid) Y(r int) float64 {
+	_, dy := g.h.CellSize()
+	return g.h.MinY + (float64(r)+0.5)*dy
+}
+
+// 统计满足条件的点的密度，保存热力图，gridPath 不为空时同时导出格网
+func execHEATMAP(ctx context.Context, directory, outPath, gridPath string, q trackstore.Query, opts trackstore.HeatmapOptions, plotOpts plotOptions) error {
+	store, err := trackstore.Open(directory, trackstore.DefaultOptions())
+	if err != nil {
+		return fmt.Errorf("读取索引表失败: %v", err)
+	}
+
+	h, err := store.Heatmap(ctx, q, opts)
+	if err != nil {
+		return fmt.Errorf("统计格网失败: %v", err)
+	}
+	unit := "点"
+	if opts.Dwell {
+		unit = "秒"
+	}
+	log.Printf("格网 %d 列 × %d 行，单元最大值 %g %s", h.Cols, h.Rows, h.Max(), unit)
+
+	p := plot.New()
+	p.Title.Text = plotOpts.Title
+	p.X.Label.Text = "x (EPSG:3857, m)"
+	p.Y.Label.Text = "y (EPSG:3857, m)"
+	hm := plotter.NewHeatMap(heatmapGrid{h}, moreland.SmoothBlueRed().Palette(255))
+	hm.NaN = color.Transparent
+	hm.Min, hm.Max = 0, h.Max()
+	if hm.Max == 0 {
+		// 格网为空时所有单元均为 NaN，避免范围为 0
+		hm.Max = 1
+	}
+	p.Add(hm)
+	if err := savePlot(p, outPath, plotOpts); err != nil {
+		return fmt.Errorf("保存热力图失败: %v", err)
+	}
+	log.Printf("热力图保存为 %s", outPath)
+
+	if gridPath == "" {
+		return nil
+	}
+	file, err := os.Create(gridPath)
+	if err != nil {
+		return fmt.Errorf("创建文件失败: %v", err)
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	if strings.ToLower(filepath.Ext(gridPath)) == ".csv" {
+		err = writeGridCSV(w, h)
+	} else {
+		err = writeASCIIGrid(w, h)
+	}
+	if err != nil {
+		return fmt.Errorf("写入格网失败: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("写入格网失败: %v", err)
+	}
+	log.Printf("格网保存为 %s", gridPath)
+	return file.Close()
+}
+
+// 每个单元一行：行号、列号、单元中心经纬度与值，第 0 行在最北边
+func writeGridCSV(w io.Writer, h *trackstore.Heatmap) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"row", "col", "lon", "lat", "value"})
+	for row := 0; row < h.Rows; row++ {
+		for col := 0; col < h.Cols; col++ {
+			lon, lat := h.CellCenter(col, row)
+			cw.Write([]string{
+				strconv.Itoa(row),
+				strconv.Itoa(col),
+				strconv.FormatFloat(lon, 'f', -1, 64),
+				strconv.FormatFloat(lat, 'f', -1, 64),
+				strconv.FormatFloat(h.Value(col, row), 'f', -1, 64),
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ESRI ASCII 栅格格式，坐标为 EPSG:3857 投影坐标（米），可直接用 GDAL/QGIS 打开。
+// 单元不是正方形（指定了行数）时用 dx、dy 代替 cellsize
+func writeASCIIGrid(w io.Writer, h *trackstore.Heatmap) error {
+	dx, dy := h.CellSize()
+	fmt.Fprintf(w, "ncols %d\nnrows %d\n", h.Cols, h.Rows)
+	fmt.Fprintf(w, "xllcorner %s\nyllcorner %s\n", formatGridFloat(h.MinX), formatGridFloat(h.MinY))
+	if math.Abs(dx-dy) <= 1e-6*dx {
+		fmt.Fprintf(w, "cellsize %s\n", formatGridFloat(dx))
+	} else {
+		fmt.Fprintf(w, "dx %s\ndy %s\n", formatGridFloat(dx), formatGridFloat(dy))
+	}
+	fmt.Fprintln(w, "NODATA_value -9999")
+	for row := 0; row < h.Rows; row++ {
+		for col := 0; col < h.Cols; col++ {
+			if col > 0 {
+				io.WriteString(w, " ")
+			}
+			io.WriteString(w, formatGridFloat(h.Value(col, row)))
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatGridFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
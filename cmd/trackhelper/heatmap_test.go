@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"os_project/trackstore"
+)
+
+// 2 列 × 2 行的格网，第 0 行在最北边，空单元的值为 0
+func tinyHeatmap(maxY float64) *trackstore.Heatmap {
+	return &trackstore.Heatmap{
+		Cols: 2, Rows: 2,
+		MinX: 1000, MinY: 2000,
+		MaxX: 1200, MaxY: maxY,
+		Values: []float64{1, 0, 2.5, 4},
+	}
+}
+
+func TestWriteASCIIGrid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		h    *trackstore.Heatmap
+		want string
+	}{
+		// xllcorner、yllcorner 为左下角单元的角点而不是中心
+		{"正方形单元", tinyHeatmap(2200), "ncols 2\nnrows 2\nxllcorner 1000\nyllcorner 2000\ncellsize 100\nNODATA_value -9999\n1 0\n2.5 4\n"},
+		{"长方形单元", tinyHeatmap(2100), "ncols 2\nnrows 2\nxllcorner 1000\nyllcorner 2000\ndx 100\ndy 50\nNODATA_value -9999\n1 0\n2.5 4\n"},
+	} {
+		var buf bytes.Buffer
+		if err := writeASCIIGrid(&buf, tc.h); err != nil {
+			t.Fatalf("%s: 写入失败: %v", tc.name, err)
+		}
+		if buf.String() != tc.want {
+			t.Errorf("%s: 输出不正确:\n%s\n期望:\n%s", tc.name, buf.String(), tc.want)
+		}
+	}
+}
+
+func TestWriteGridCSV(t *testing.T) {
+	// 以 (0, 0) 为中心、边长 2000 米的格网，单元中心在 (±500, ±500) 米
+	h := &trackstore.Heatmap{Cols: 2, Rows: 2, MinX: -1000, MinY: -1000, MaxX: 1000, MaxY: 1000, Values: []float64{1, 0, 2.5, 4}}
+
+	var buf bytes.Buffer
+	if err := writeGridCSV(&buf, h); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	// 第 0 行在北、第 0 列在西
+	want := "row,col,lon,lat,value\n" +
+		"0,0,-0.004491576420597607,0.004491576415997162,1\n" +
+		"0,1,0.004491576420597607,0.004491576415997162,0\n" +
+		"1,0,-0.004491576420597607,-0.004491576415997162,2.5\n" +
+		"1,1,0.004491576420597607,-0.004491576415997162,4\n"
+	if buf.String() != want {
+		t.Errorf("输出不正确:\n%s\n期望:\n%s", buf.String(), want)
+	}
+}
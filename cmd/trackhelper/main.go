@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"strings"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"runtime/trace"
+	"syscall"
+
+	"os_project/trackstore"
+)
+
+// ctx 取消后不再派发新的数据块，已清洗完的数据块仍会写入，最后保存索引表
+func execSTORE(ctx context.Context, excelPath, directory, trajectory string, opts trackstore.Options) error {
+	// 读取数据
+	points, err := trackstore.ReadTrack(excelPath)
+	var readErr *trackstore.ReadError
+	if errors.As(err, &readErr) {
+		// 个别记录或字段无法解析时仍写入其余的点
+		log.Printf("读取数据: %v", err)
+	} else if err != nil {
+		return fmt.Errorf("读取数据失败: %v", err)
+	}
+
+	store, err := trackstore.Open(directory, opts)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	report, err := store.Append(ctx, trajectory, points)
+	report.Print()
+	if err != nil {
+		if ctx.Err() != nil {
+			log.Println("任务已中断，索引表已保存")
+		}
+		return err
+	}
+	log.Println("所有任务处理完成")
+	return store.Close()
+}
+
+// READ 的空间查询参数
+type readOptions struct {
+	Radius   float64             // 大于 0 时查询每个给定点周围该半径（米）内的点
+	Polygon  *trackstore.Polygon // 只保留多边形内的点
+	K        int                 // 大于 0 时查询距离每个给定点最近的 K 个点
+	Compare  bool                // 对比原始轨迹与清洗后的轨迹
+	Distance trackstore.Metric   // -radius 与 -k 使用的距离度量
+	CRS      trackstore.CRS      // 给定点已转换为 WGS84，-k 输出的坐标再转换回该坐标系
+	Profile  string              // 不为空时同时将高程剖面图保存到该路径
+}
+
+// READ 的轨迹图输出参数
+type plotOptions struct {
+	Title      string
+	Width      vg.Length
+	Height     vg.Length
+	Format     string // 图片格式，为空时按输出文件扩展名确定
+	Projection string // 绘图投影，见 trackstore.NewProjection
+}
+
+// 一个查询任务：q 为空时查询外包矩形包含 pt 的数据块
+type readTask struct {
+	pt trackstore.Point
+	q  *trackstore.Query
+	k  int
+}
+
+func execREAD(ctx context.Context, points []trackstore.Point, directory, outPath string, numThreads int, opts readOptions, plotOpts plotOptions) error {
+    storeOpts := trackstore.DefaultOptions()
+    storeOpts.Distance = opts.Distance
+    if opts.CRS == nil {
+        opts.CRS = trackstore.WGS84{}
+    }
+    if opts.Distance == nil {
+        opts.Distance = trackstore.Haversine
+    }
+    store, err := trackstore.Open(directory, storeOpts)
+    if err != nil {
+        return fmt.Errorf("读取索引表失败: %v", err)
+    }
+
+    // 各 goroutine 只收集查询结果，绘图在全部查询结束后进行
+    collector := newTrackCollector()
+
+    var tasks []readTask
+    switch {
+    case opts.K > 0:
+        for _, pt := range points {
+            tasks = append(tasks, readTask{pt: pt, k: opts.K})
+        }
+    case opts.Radius > 0:
+        for _, pt := range points {
+            circle := trackstore.Circle{Center: pt, Radius: opts.Radius}
+            tasks = append(tasks, readTask{pt: pt, q: &trackstore.Query{Radius: &circle, Polygon: opts.Polygon}})
+        }
+    case opts.Polygon != nil:
+        tasks = append(tasks, readTask{q: &trackstore.Query{Polygon: opts.Polygon}})
+    default:
+        for _, pt := range points {
+            tasks = append(tasks, readTask{pt: pt})
+        }
+    }
+
+    pointCh := make(chan readTask, len(tasks))
+
+    var wg sync.WaitGroup
+
+    for i := 0; i < numThreads; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for task := range pointCh {
+                if ctx.Err() != nil {
+                    return
+                }
+                if task.k > 0 {
+                    plotNearest(ctx, store, task.pt, task.k, opts.CRS, collector)
+                    continue
+                }
+                if task.q != nil {
+                    plotQuery(ctx, store, *task.q, collector)
+                    continue
+                }
+                searchAndPlotPoints(store, task.pt, collector)
+            }
+        }()
+    }
+
+    for _, task := range tasks {
+        pointCh <- task
+    }
+    close(pointCh)
+    wg.Wait()
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    p, err := collector.plot(plotOpts.Title, opts.Compare, plotOpts.Projection)
+    if err != nil {
+        return err
+    }
+    if err := savePlot(p, outPath, plotOpts); err != nil {
+        return fmt.Errorf("保存图表失败: %v", err)
+    }
+    log.Printf("轨迹图保存为 %s", outPath)
+
+    if opts.Profile != "" {
+        profile, err := collector.profile("Elevation profile", opts.Distance)
+        if err != nil {
+            return err
+        }
+        if err := savePlot(profile, opts.Profile, plotOpts); err != nil {
+            return fmt.Errorf("保存高程剖面图失败: %v", err)
+        }
+        log.Printf("高程剖面图保存为 %s", opts.Profile)
+    }
+    return nil
+}
+
+// 未指定格式时由 plot.Save 按扩展名确定格式，否则按指定格式写入
+func savePlot(p *plot.Plot, path string, opts plotOptions) error {
+    if opts.Format == "" {
+        return p.Save(opts.Width, opts.Height, path)
+    }
+    w, err := p.WriterTo(opts.Width, opts.Height, opts.Format)
+    if err != nil {
+        return err
+    }
+    f, err := os.Create(path)
+    if err != nil {
+        return err
+    }
+    if _, err := w.WriteTo(f); err != nil {
+        f.Close()
+        return err
+    }
+    return f.Close()
+}
+
+
+// 开启运行轨迹记录，返回的函数用于停止记录；path 为空时不记录
+func traceGO(path string) (func(), error) {
+	if path == "" {
+		return func() {}, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("创建 trace 文件失败: %v", err)
+	}
+
+	if err := trace.Start(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("开启 trace 失败: %v", err)
+	}
+	return func() {
+		trace.Stop()
+		f.Close()
+	}, nil
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "--help" || os.Args[1] == "help" {
+		printHelp()
+		if len(os.Args) < 2 {
+			os.Exit(2)
+		}
+		return
+	}
+
+	// 兼容旧版本的大写模式名 STORE / READ 等
+	cmd, ok := lookupCommand(strings.ToLower(os.Args[1]))
+	if !ok {
+		fmt.Fprintf(os.Stderr, "ERROR: Unknown command %q\n", os.Args[1])
+		printHelp()
+		os.Exit(2)
+	}
+
+	// 第一次 SIGINT/SIGTERM 取消 ctx 并等待流水线收尾，再次收到信号时按默认行为立即退出
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := cmd.Run(ctx, os.Args[2:])
+	stop()
+	var uerr *usageError
+	switch {
+	case err == nil:
+	case errors.Is(err, flag.ErrHelp):
+	case errors.As(err, &uerr):
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(2)
+	case errors.Is(err, context.Canceled):
+		fmt.Fprintln(os.Stderr, "ERROR: 已中断")
+		os.Exit(130)
+	case errors.Is(err, context.DeadlineExceeded):
+		fmt.Fprintln(os.Stderr, "ERROR: 运行超时")
+		os.Exit(1)
+	default:
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		os.Exit(1)
+	}
+}
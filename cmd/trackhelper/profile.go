@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+
+	"os_project/trackstore"
+)
+
+// 高程剖面：横轴为沿轨迹的累计水平距离（千米），纵轴为海拔（米），
+// 每条轨迹一种颜色，没有海拔的点处断开
+func (c *trackCollector) profile(title string, metric trackstore.Metric) (*plot.Plot, error) {
+	p := plot.New()
+	p.Title.Text = title
+	p.X.Label.Text = "distance (km)"
+	p.Y.Label.Text = "altitude (m)"
+	p.Legend.Top = true
+
+	colors := make(map[string]color.Color)
+	found := false
+	for _, run := range c.runs() {
+		col, ok := colors[run.trajectory]
+		if !ok {
+			col = plotutil.Color(len(colors))
+			colors[run.trajectory] = col
+		}
+
+		var segments []plotter.XYs
+		var xys plotter.XYs
+		dist := 0.0
+		for i, pt := range run.points {
+			if i > 0 {
+				dist += metric(run.points[i-1], pt)
+			}
+			if !pt.HasAltitude {
+				if len(xys) > 0 {
+					segments = append(segments, xys)
+					xys = nil
+				}
+				continue
+			}
+			xys = append(xys, plotter.XY{X: dist / 1000, Y: pt.Altitude})
+		}
+		if len(xys) > 0 {
+			segments = append(segments, xys)
+		}
+
+		for i, seg := range segments {
+			line, err := plotter.NewLine(seg)
+			if err != nil {
+				return nil, fmt.Errorf("创建折线失败: %v", err)
+			}
+			line.LineStyle.Color = col
+			line.LineStyle.Width = vg.Points(1.5)
+			p.Add(line)
+			if !ok && i == 0 {
+				name := run.trajectory
+				if name == "" {
+					name = "(default)"
+				}
+				p.Legend.Add(name, line)
+				ok = true
+			}
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("查询到的点都没有海拔，无法绘制高程剖面")
+	}
+	return p, nil
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/plotutil"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+
+	"os_project/trackstore"
+)
+
+// 以各组点外包矩形的中心为参考点创建绘图用的投影
+func plotProjection(name string, groups ...[]trackstore.Point) (trackstore.Projection, error) {
+    found := false
+    var lo, hi trackstore.Point
+    for _, pts := range groups {
+        for _, p := range pts {
+            if !found {
+                lo, hi, found = p, p, true
+                continue
+            }
+            lo.Longitude, lo.Latitude = min(lo.Longitude, p.Longitude), min(lo.Latitude, p.Latitude)
+            hi.Longitude, hi.Latitude = max(hi.Longitude, p.Longitude), max(hi.Latitude, p.Latitude)
+        }
+    }
+    origin := trackstore.Point{Longitude: (lo.Longitude + hi.Longitude) / 2, Latitude: (lo.Latitude + hi.Latitude) / 2}
+    return trackstore.NewProjection(name, origin)
+}
+
+// 投影后的绘图坐标（米）
+func plotXY(proj trackstore.Projection, p trackstore.Point) (x, y float64) {
+    return proj.Forward(p.Longitude, p.Latitude)
+}
+
+// 坐标轴标题注明投影，默认字体不含中文字形，标签使用英文
+func setAxisLabels(p *plot.Plot, proj trackstore.Projection) {
+    p.X.Label.Text = fmt.Sprintf("x (%s, m)", proj.Name())
+    p.Y.Label.Text = fmt.Sprintf("y (%s, m)", proj.Name())
+}
+
+// 汇总各查询 goroutine 找到的数据块与最近点，全部查询结束后统一绘图
+type trackCollector struct {
+    mu     sync.Mutex
+    chunks map[int]trackstore.Chunk // 按任务号去重，多个查询点可能命中同一数据块
+    marks  []trackstore.Point       // 最近点查询的结果
+}
+
+func newTrackCollector() *trackCollector {
+    return &trackCollector{chunks: make(map[int]trackstore.Chunk)}
+}
+
+// 同一数据块被不同的圆形查询命中时，保留点数较多的结果
+func (c *trackCollector) addChunk(chunk trackstore.Chunk) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if old, ok := c.chunks[chunk.Meta.TaskIdx]; ok && len(old.Points) >= len(chunk.Points) {
+        return
+    }
+    c.chunks[chunk.Meta.TaskIdx] = chunk
+}
+
+// 输出距离 pt 最近的 k 个点，坐标转换到 crs 输出，并记录下来用于绘图
+func plotNearest(ctx context.Context, store *trackstore.Store, pt trackstore.Point, k int, crs trackstore.CRS, c *trackCollector) {
+    neighbors, err := store.Nearest(ctx, pt, k)
+    if err != nil {
+        log.Printf("查询点 (%f, %f) 的最近点失败: %v\n", pt.Longitude, pt.Latitude, err)
+        return
+    }
+
+    var sb strings.Builder
+    x, y := crs.FromWGS84(pt.Longitude, pt.Latitude)
+    fmt.Fprintf(&sb, "距离点 (%f, %f) 最近的 %d 个点:\n", x, y, len(neighbors))
+    for i, n := range neighbors {
+        x, y := crs.FromWGS84(n.Point.Longitude, n.Point.Latitude)
+        fmt.Fprintf(&sb, "%3d  %10.2fm  (%f, %f)  轨迹 %s  数据块 %d  第 %d 个点", i+1, n.Distance, x, y, n.Trajectory, n.TaskIdx, n.Index)
+        if !n.Point.Time.IsZero() {
+            sb.WriteString("  " + n.Point.Time.Format(time.RFC3339))
+        }
+        sb.WriteByte('\n')
+    }
+
+    // 同一把锁保证各查询点的输出不交错
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    fmt.Print(sb.String())
+    for _, n := range neighbors {
+        c.marks = append(c.marks, n.Point)
+    }
+}
+
+// 记录满足查询条件的数据块
+func plotQuery(ctx context.Context, store *trackstore.Store, q trackstore.Query, c *trackCollector) {
+    total := 0
+    for chunk, err := range store.QueryChunks(ctx, q) {
+        if err != nil {
+            log.Printf("查询失败: %v\n", err)
+            continue
+        }
+        total += len(chunk.Points)
+        c.addChunk(chunk)
+    }
+
+    if q.Radius != nil {
+        log.Printf("点 (%f, %f) 周围 %.0f 米内共 %d 个点", q.Radius.Center.Longitude, q.Radius.Center.Latitude, q.Radius.Radius, total)
+    } else {
+        log.Printf("多边形内共 %d 个点", total)
+    }
+}
+
+func searchAndPlotPoints(store *trackstore.Store, pt trackstore.Point, c *trackCollector) {
+    chunk, err := store.LookupChunk(pt)
+    if err != nil {
+        log.Printf("查询点 (%f, %f) 失败: %v\n", pt.Longitude, pt.Latitude, err)
+        return
+    }
+    c.addChunk(chunk)
+}
+
+// 同一轨迹中序号连续的数据块拼成的一段折线
+type trackRun struct {
+    trajectory string
+    points     []trackstore.Point
+}
+
+// 按轨迹名、数据块序号排序后拼接，序号不连续处断开
+func (c *trackCollector) runs() []trackRun {
+    chunks := make([]trackstore.Chunk, 0, len(c.chunks))
+    for _, chunk := range c.chunks {
+        chunks = append(chunks, chunk)
+    }
+    sort.Slice(chunks, func(i, j int) bool {
+        a, b := chunks[i].Meta, chunks[j].Meta
+        if a.Trajectory != b.Trajectory {
+            return a.Trajectory < b.Trajectory
+        }
+        return a.Seq < b.Seq
+    })
+
+    var runs []trackRun
+    for i, chunk := range chunks {
+        if i == 0 || !chunk.Meta.Follows(chunks[i-1].Meta) {
+            runs = append(runs, trackRun{trajectory: chunk.Meta.Trajectory})
+        }
+        run := &runs[len(runs)-1]
+        run.points = append(run.points, chunk.Points...)
+    }
+    return runs
+}
+
+// 每条轨迹画成一种颜色的折线，图例中每条轨迹出现一次；
+// compare 为 true 时改为叠加绘制原始轨迹与清洗后的轨迹
+func (c *trackCollector) plot(title string, compare bool, projection string) (*plot.Plot, error) {
+    runs := c.runs()
+    groups := [][]trackstore.Point{c.marks}
+    for _, run := range runs {
+        groups = append(groups, run.points)
+    }
+    proj, err := plotProjection(projection, groups...)
+    if err != nil {
+        return nil, err
+    }
+
+    p := plot.New()
+    p.Title.Text = title
+    setAxisLabels(p, proj)
+    p.Legend.Top = true
+
+    colors := make(map[string]color.Color)
+    for _, run := range runs {
+        if compare {
+            if err := plotComparison(run.points, p, proj); err != nil {
+                return nil, err
+            }
+            continue
+        }
+
+        xys := make(plotter.XYs, len(run.points))
+        for i, pt := range run.points {
+            xys[i].X, xys[i].Y = plotXY(proj, pt)
+        }
+        line, scatter, err := plotter.NewLinePoints(xys)
+        if err != nil {
+            return nil, fmt.Errorf("创建折线失败: %v", err)
+        }
+        col, ok := colors[run.trajectory]
+        if !ok {
+            col = plotutil.Color(len(colors))
+            colors[run.trajectory] = col
+            name := run.trajectory
+            if name == "" {
+                name = "(default)"
+            }
+            p.Legend.Add(name, line)
+        }
+        line.LineStyle.Color = col
+        line.LineStyle.Width = vg.Points(1.5)
+        scatter.GlyphStyle = draw.GlyphStyle{Color: col, Radius: vg.Points(1.2), Shape: draw.CircleGlyph{}}
+        p.Add(line, scatter)
+    }
+    if compare {
+        addComparisonLegend(p)
+    }
+
+    if len(c.marks) > 0 {
+        xys := make(plotter.XYs, len(c.marks))
+        for i, pt := range c.marks {
+            xys[i].X, xys[i].Y = plotXY(proj, pt)
+        }
+        scatter, err := plotter.NewScatter(xys)
+        if err != nil {
+            return nil, fmt.Errorf("创建散点图失败: %v", err)
+        }
+        scatter.GlyphStyle = draw.GlyphStyle{Color: color.Black, Radius: vg.Points(3), Shape: draw.CrossGlyph{}}
+        p.Add(scatter)
+        p.Legend.Add("nearest", scatter)
+    }
+    return p, nil
+}
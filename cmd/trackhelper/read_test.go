@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"os_project/trackstore"
+)
+
+func TestTrackCollectorRuns(t *testing.T) {
+	start := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	// 轨迹 trajectory 的第 seq 个数据块，两个点的时间从 offset 开始
+	chunk := func(taskIdx int, trajectory string, seq int, offset time.Duration) trackstore.Chunk {
+		t0 := start.Add(offset)
+		return trackstore.Chunk{
+			Meta: trackstore.ChunkMeta{TaskIdx: taskIdx, Trajectory: trajectory, Seq: seq},
+			Points: []trackstore.Point{
+				{Longitude: float64(seq), Time: t0},
+				{Longitude: float64(seq) + 0.5, Time: t0.Add(time.Second)},
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		chunks []trackstore.Chunk
+		runs   []string // 每段折线的轨迹名
+		counts []int    // 每段折线的点数
+	}{
+		{"序号连续", []trackstore.Chunk{chunk(0, "a", 0, 0), chunk(1, "a", 1, 2*time.Second), chunk(2, "a", 2, 4*time.Second)},
+			[]string{"a"}, []int{6}},
+		{"乱序加入", []trackstore.Chunk{chunk(2, "a", 2, 4*time.Second), chunk(0, "a", 0, 0), chunk(1, "a", 1, 2*time.Second)},
+			[]string{"a"}, []int{6}},
+		// 只按序号断开，序号连续时时间间隔再大也连成一段
+		{"时间间隔", []trackstore.Chunk{chunk(0, "a", 0, 0), chunk(1, "a", 1, 24*time.Hour)},
+			[]string{"a"}, []int{4}},
+		{"序号不连续", []trackstore.Chunk{chunk(0, "a", 0, 0), chunk(1, "a", 1, 2*time.Second), chunk(3, "a", 3, 6*time.Second)},
+			[]string{"a", "a"}, []int{4, 2}},
+		{"不同轨迹", []trackstore.Chunk{chunk(0, "b", 0, 0), chunk(1, "a", 0, 0), chunk(2, "b", 1, 2*time.Second)},
+			[]string{"a", "b"}, []int{2, 4}},
+		{"不同轨迹序号相接", []trackstore.Chunk{chunk(0, "a", 0, 0), chunk(1, "b", 1, 0)},
+			[]string{"a", "b"}, []int{2, 2}},
+	} {
+		c := newTrackCollector()
+		for _, chunk := range tc.chunks {
+			c.addChunk(chunk)
+		}
+		runs := c.runs()
+		if len(runs) != len(tc.runs) {
+			t.Errorf("%s: 应有 %d 段折线，实际 %d 段", tc.name, len(tc.runs), len(runs))
+			continue
+		}
+		for i, run := range runs {
+			if run.trajectory != tc.runs[i] || len(run.points) != tc.counts[i] {
+				t.Errorf("%s: 第 %d 段应为轨迹 %s 的 %d 个点，实际轨迹 %s 的 %d 个点", tc.name, i, tc.runs[i], tc.counts[i], run.trajectory, len(run.points))
+			}
+			for j := 1; j < len(run.points); j++ {
+				if !run.points[j].Time.After(run.points[j-1].Time) {
+					t.Errorf("%s: 第 %d 段的点未按序号排列", tc.name, i)
+					break
+				}
+			}
+		}
+	}
+
+	// 序号不连续的两段分别绘制，不应连成一条线
+	c := newTrackCollector()
+	c.addChunk(chunk(0, "a", 0, 0))
+	c.addChunk(chunk(1, "a", 2, 4*time.Second))
+	if _, err := c.plot("test", false, "webmercator"); err != nil {
+		t.Errorf("绘图失败: %v", err)
+	}
+	if _, err := c.plot("test", true, "webmercator"); err != nil {
+		t.Errorf("绘制对比图失败: %v", err)
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"os_project/trackrpc"
+	"os_project/trackstore"
+)
+
+// HTTP 查询服务：存储只打开一次，各请求共享同一个 Store 及其数据块缓存
+type server struct {
+	store     *trackstore.Store
+	opts      trackstore.Options // 清洗预览使用的划分参数
+	maxPoints int                // 单次查询最多返回的点数
+}
+
+func newServer(store *trackstore.Store, opts trackstore.Options, maxPoints int) http.Handler {
+	s := &server{store: store, opts: opts, maxPoints: maxPoints}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /stats", s.handleStats)
+	mux.HandleFunc("GET /chunks", s.handleChunks)
+	mux.HandleFunc("GET /query", s.handleQuery)
+	mux.HandleFunc("GET /tiles/{z}/{x}/{y}", s.handleTile)
+	mux.HandleFunc("POST /preview", s.handlePreview)
+	mux.Handle("GET /", viewerHandler())
+	// 地图页面通常与本服务不同源
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("写入响应失败: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// 没有定位时间时为 nil，JSON 中省略
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+type statsResponse struct {
+	Chunks       int        `json:"chunks"`
+	Points       int        `json:"points"`
+	Trajectories int        `json:"trajectories"`
+	BBox         [4]float64 `json:"bbox"` // 最小经度, 最小纬度, 最大经度, 最大纬度
+	StartTime    *time.Time `json:"start_time,omitempty"`
+	EndTime      *time.Time `json:"end_time,omitempty"`
+	CacheHits    uint64     `json:"cache_hits"`
+	CacheMisses  uint64     `json:"cache_misses"`
+	CacheChunks  int        `json:"cache_chunks"`
+}
+
+func (s *server) handleStats(w http.ResponseWriter, r *http.Request) {
+	st := s.store.Stats()
+	writeJSON(w, http.StatusOK, statsResponse{
+		Chunks:       st.Chunks,
+		Points:       st.Points,
+		Trajectories: st.Trajectories,
+		BBox:         [4]float64{st.Min.Longitude, st.Min.Latitude, st.Max.Longitude, st.Max.Latitude},
+		StartTime:    timePtr(st.StartTime),
+		EndTime:      timePtr(st.EndTime),
+		CacheHits:    st.CacheHits,
+		CacheMisses:  st.CacheMisses,
+		CacheChunks:  st.CacheChunks,
+	})
+}
+
+type chunkResponse struct {
+	TaskIdx    int        `json:"task"`
+	Trajectory string     `json:"trajectory"`
+	Seq        int        `json:"seq"`
+	Count      int        `json:"count"`
+	BBox       [4]float64 `json:"bbox"`
+	StartTime  *time.Time `json:"start_time,omitempty"`
+	EndTime    *time.Time `json:"end_time,omitempty"`
+}
+
+// 数据块元信息，可用 ?trajectory= 只列出一条轨迹
+func (s *server) handleChunks(w http.ResponseWriter, r *http.Request) {
+	trajectory := r.URL.Query().Get("trajectory")
+	chunks := []chunkResponse{}
+	for _, meta := range s.store.Index().Metas() {
+		if trajectory != "" && meta.Trajectory != trajectory {
+			continue
+		}
+		chunks = append(chunks, chunkResponse{
+			TaskIdx:    meta.TaskIdx,
+			Trajectory: meta.Trajectory,
+			Seq:        meta.Seq,
+			Count:      meta.Count,
+			BBox:       [4]float64{meta.Min.Longitude, meta.Min.Latitude, meta.Max.Longitude, meta.Max.Latitude},
+			StartTime:  timePtr(meta.StartTime),
+			EndTime:    timePtr(meta.EndTime),
+		})
+	}
+	writeJSON(w, http.StatusOK, chunks)
+}
+
+type geoJSONGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"` // 有海拔时为 [经度, 纬度, 海拔]
+}
+
+type geoJSONFeature struct {
+	Type       string          `json:"type"`
+	Geometry   geoJSONGeometry `json:"geometry"`
+	Properties pointProperties `json:"properties"`
+}
+
+type pointProperties struct {
+	Trajectory string     `json:"trajectory"`
+	TaskIdx    int        `json:"task"`
+	Time       *time.Time `json:"time,omitempty"`
+	Flag       string     `json:"flag,omitempty"` // 清洗修改过的点为 interpolated 或 smoothed
+	*exportAttrs
+}
+
+type featureCollection struct {
+	Type      string           `json:"type"`
+	Features  []geoJSONFeature `json:"features"`
+	Truncated bool             `json:"truncated,omitempty"` // 结果超过 limit 被截断
+}
+
+// 按查询参数返回 GeoJSON 点集：
+// bbox=最小经度,最小纬度,最大经度,最大纬度；lon、lat、radius（米）；polygon（WKT 或 GeoJSON）；
+// since、until（RFC3339）；trajectory；limit
+func (s *server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	q, limit, err := s.parseQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result := featureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	for chunk, err := range s.store.QueryChunks(r.Context(), q) {
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		for _, p := range chunk.Points {
+			if len(result.Features) == limit {
+				result.Truncated = true
+				break
+			}
+			props := pointProperties{
+				Trajectory: chunk.Meta.Trajectory,
+				TaskIdx:    chunk.Meta.TaskIdx,
+				Time:       timePtr(p.Time),
+			}
+			if p.Flag != trackstore.FlagOriginal {
+				props.Flag = p.Flag.String()
+			}
+			props.exportAttrs = newExportAttrs(p.Attrs)
+			coords := []float64{p.Longitude, p.Latitude}
+			if p.HasAltitude {
+				coords = append(coords, p.Altitude)
+			}
+			result.Features = append(result.Features, geoJSONFeature{
+				Type:       "Feature",
+				Geometry:   geoJSONGeometry{"Point", coords},
+				Properties: props,
+			})
+		}
+		if result.Truncated {
+			break
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// Mapbox Vector Tile 瓦片 /tiles/{z}/{x}/{y}.mvt，可用 ?trajectory= 只显示一条轨迹
+func (s *server) handleTile(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutSuffix(r.PathValue("y"), ".mvt")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	z, errZ := strconv.Atoi(r.PathValue("z"))
+	x, errX := strconv.Atoi(r.PathValue("x"))
+	y, errY := strconv.Atoi(name)
+	if errZ != nil || errX != nil || errY != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("瓦片编号应为整数: %s", r.URL.Path))
+		return
+	}
+
+	tile, err := s.store.Tile(r.Context(), z, x, y, r.URL.Query().Get("trajectory"))
+	if errors.Is(err, trackstore.ErrInvalidTile) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/vnd.mapbox-vector-tile")
+	w.Write(tile)
+}
+
+func parseFloats(value string, n int) ([]float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("应有 %d 个数值: %s", n, value)
+	}
+	values := make([]float64, n)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("不是数值: %s", part)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func (s *server) parseQuery(r *http.Request) (trackstore.Query, int, error) {
+	values := r.URL.Query()
+	q := trackstore.Query{Trajectory: values.Get("trajectory")}
+
+	if v := values.Get("bbox"); v != "" {
+		b, err := parseFloats(v, 4)
+		if err != nil {
+			return q, 0, fmt.Errorf("bbox %v", err)
+		}
+		q.BBox = &trackstore.BBox{
+			Min: trackstore.Point{Longitude: b[0], Latitude: b[1]},
+			Max: trackstore.Point{Longitude: b[2], Latitude: b[3]},
+		}
+	}
+
+	if v := values.Get("radius"); v != "" {
+		radius, err := strconv.ParseFloat(v, 64)
+		if err != nil || radius <= 0 {
+			return q, 0, fmt.Errorf("radius 应为正数: %s", v)
+		}
+		center, err := parseFloats(values.Get("lon")+","+values.Get("lat"), 2)
+		if err != nil {
+			return q, 0, fmt.Errorf("radius 需要 lon 和 lat: %v", err)
+		}
+		q.Radius = &trackstore.Circle{
+			Center: trackstore.Point{Longitude: center[0], Latitude: center[1]},
+			Radius: radius,
+		}
+	}
+
+	if v := values.Get("polygon"); v != "" {
+		pg, err := trackstore.ParsePolygon(v)
+		if err != nil {
+			return q, 0, fmt.Errorf("polygon 无效: %v", err)
+		}
+		q.Polygon = &pg
+	}
+
+	var err error
+	if q.Since, err = parseTimeFlag("since", values.Get("since")); err != nil {
+		return q, 0, err
+	}
+	if q.Until, err = parseTimeFlag("until", values.Get("until")); err != nil {
+		return q, 0, err
+	}
+
+	limit := s.maxPoints
+	if v := values.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return q, 0, fmt.Errorf("limit 应为正整数: %s", v)
+		}
+		limit = min(n, s.maxPoints)
+	}
+	return q, limit, nil
+}
+
+// 启动 HTTP 服务，grpcAddr 不为空时同时提供 gRPC 服务，两者共享同一个 Store。
+// ctx 取消后停止接收新连接并等待进行中的请求完成
+func execSERVE(ctx context.Context, directory, addr, grpcAddr string, opts trackstore.Options, maxPoints, maxIngest int) error {
+	store, err := trackstore.Open(directory, opts)
+	if err != nil {
+		return fmt.Errorf("读取索引表失败: %v", err)
+	}
+	defer store.Close()
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("监听 %s 失败: %v", addr, err)
+	}
+	srv := &http.Server{
+		Handler:           newServer(store, opts, maxPoints),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- srv.Serve(listener)
+	}()
+	log.Printf("服务已启动: http://%s", listener.Addr())
+
+	var grpcSrv *grpc.Server
+	if grpcAddr != "" {
+		grpcListener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			srv.Close()
+			return fmt.Errorf("监听 %s 失败: %v", grpcAddr, err)
+		}
+		grpcSrv = grpc.NewServer()
+		trackrpc.RegisterTrackServiceServer(grpcSrv, trackrpc.NewServer(store, maxIngest))
+		go func() {
+			if err := grpcSrv.Serve(grpcListener); err != nil {
+				errCh <- err
+			}
+		}()
+		log.Printf("gRPC 服务已启动: %s", grpcListener.Addr())
+	}
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if grpcSrv != nil {
+		// 等待进行中的流结束，超时后强制关闭
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			grpcSrv.Stop()
+		}
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("停止服务失败: %v", err)
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	log.Println("服务已停止")
+	return nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tealeg/xlsx"
+
+	"os_project/trackstore"
+)
+
+// 沿经度方向均匀分布的测试轨迹
+func linePoints(n int, lon0 float64) []trackstore.Point {
+	points := make([]trackstore.Point, n)
+	for i := range points {
+		points[i] = trackstore.Point{Longitude: lon0 + float64(i)*0.0003, Latitude: 30.0}
+	}
+	return points
+}
+
+// 写入两条轨迹的 HTTP 服务
+func newTestServer(t *testing.T, maxPoints int) (http.Handler, *trackstore.Store) {
+	t.Helper()
+	opts := trackstore.DefaultOptions()
+	opts.CacheChunks = 8
+	store, err := trackstore.Open(t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	for name, lon := range map[string]float64{"a": 120.0, "b": 121.0} {
+		if _, err := store.Append(context.Background(), name, linePoints(40, lon)); err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+	}
+	return newServer(store, opts, maxPoints), store
+}
+
+func get(t *testing.T, h http.Handler, url string, v any) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	if v != nil && rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s: 解析响应失败: %v", url, err)
+		}
+	}
+	return rec
+}
+
+func TestHandleStatsAndChunks(t *testing.T) {
+	h, store := newTestServer(t, 1000)
+
+	var st statsResponse
+	if rec := get(t, h, "/stats", &st); rec.Code != http.StatusOK {
+		t.Fatalf("/stats 返回 %d", rec.Code)
+	}
+	if st.Points != 80 || st.Trajectories != 2 || st.Chunks != len(store.Index().Chunks) {
+		t.Errorf("/stats 不正确: %+v", st)
+	}
+	if st.BBox != [4]float64{120.0, 30.0, 121.0 + 39*0.0003, 30.0} {
+		t.Errorf("/stats 外包矩形不正确: %v", st.BBox)
+	}
+
+	var chunks []chunkResponse
+	get(t, h, "/chunks?trajectory=b", &chunks)
+	if len(chunks) == 0 {
+		t.Fatalf("/chunks 应返回轨迹 b 的数据块")
+	}
+	for i, c := range chunks {
+		if c.Trajectory != "b" || c.Seq != i {
+			t.Errorf("/chunks 第 %d 项应为轨迹 b 的第 %d 个数据块: %+v", i, i, c)
+		}
+	}
+}
+
+func TestHandleQuery(t *testing.T) {
+	h, _ := newTestServer(t, 1000)
+
+	for _, tc := range []struct {
+		url       string
+		count     int
+		truncated bool
+	}{
+		{"/query?trajectory=a", 40, false},
+		{"/query?bbox=120.0,29.9,120.003,30.1", 11, false},
+		{"/query?lon=121.0&lat=30.0&radius=100", 4, false},
+		{"/query?limit=5", 5, true},
+	} {
+		var fc featureCollection
+		if rec := get(t, h, tc.url, &fc); rec.Code != http.StatusOK {
+			t.Errorf("%s 返回 %d: %s", tc.url, rec.Code, rec.Body)
+			continue
+		}
+		if len(fc.Features) != tc.count || fc.Truncated != tc.truncated {
+			t.Errorf("%s: 期望 %d 个点（截断 %v），实际 %d 个（截断 %v）", tc.url, tc.count, tc.truncated, len(fc.Features), fc.Truncated)
+		}
+	}
+
+	for _, url := range []string{"/query?bbox=1,2,3", "/query?radius=100", "/query?limit=0", "/query?since=yesterday"} {
+		if rec := get(t, h, url, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s 应返回 400，实际 %d", url, rec.Code)
+		}
+	}
+}
+
+func TestHandleTile(t *testing.T) {
+	h, _ := newTestServer(t, 1000)
+
+	mx, my := trackstore.Mercator(120.006, 30.0)
+	const z = 14
+	x, y := int(math.Floor(mx*(1<<z))), int(math.Floor(my*(1<<z)))
+	rec := get(t, h, fmt.Sprintf("/tiles/%d/%d/%d.mvt", z, x, y), nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/vnd.mapbox-vector-tile" || rec.Body.Len() == 0 {
+		t.Errorf("包含轨迹的瓦片应返回非空 MVT: %d %q %d 字节", rec.Code, rec.Header().Get("Content-Type"), rec.Body.Len())
+	}
+	if rec := get(t, h, fmt.Sprintf("/tiles/%d/%d/%d.mvt", z, x+100, y), nil); rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("没有轨迹的瓦片应为空: %d %d 字节", rec.Code, rec.Body.Len())
+	}
+
+	for url, code := range map[string]int{
+		"/tiles/1/0/a.mvt": http.StatusBadRequest,
+		"/tiles/1/2/0.mvt": http.StatusNotFound,
+		"/tiles/1/0/0.png": http.StatusNotFound,
+	} {
+		if rec := get(t, h, url, nil); rec.Code != code {
+			t.Errorf("%s 应返回 %d，实际 %d", url, code, rec.Code)
+		}
+	}
+}
+
+func TestHandlePreview(t *testing.T) {
+	h, _ := newTestServer(t, 1000)
+
+	// 第 10 个点偏离约 110 米
+	file := xlsx.NewFile()
+	sheet, _ := file.AddSheet("track")
+	points := linePoints(20, 120.0)
+	points[10].Latitude += 0.001
+	for _, p := range points {
+		row := sheet.AddRow()
+		row.AddCell().SetFloat(p.Longitude)
+		row.AddCell().SetFloat(p.Latitude)
+	}
+	// 经度无法解析的行跳过，海拔无法解析时该点保留
+	bad := sheet.AddRow()
+	bad.AddCell().SetString("bad")
+	bad.AddCell().SetFloat(30.0)
+	last := sheet.Rows[len(points)-1]
+	last.AddCell()
+	last.AddCell().SetString("high")
+	resp := postPreview(t, h, "track.xlsx", file.Write)
+	if len(resp.Raw) != len(points) || len(resp.Cleaned) != len(points) || len(resp.Outliers) == 0 {
+		t.Fatalf("预览结果不正确: %d %d %v", len(resp.Raw), len(resp.Cleaned), resp.Outliers)
+	}
+	if resp.Skipped != 1 || resp.BadFields != 1 {
+		t.Errorf("应报告跳过 1 行、留空 1 个单元格，实际 %d %d", resp.Skipped, resp.BadFields)
+	}
+	for _, i := range resp.Outliers {
+		if resp.Raw[i] != [2]float64{points[i].Longitude, points[i].Latitude} || resp.Raw[i] == resp.Cleaned[i] {
+			t.Errorf("异常点 %d 的原始坐标应为上传的坐标，清洗后应不同: %v %v", i, resp.Raw[i], resp.Cleaned[i])
+		}
+	}
+
+	// GPX 文件按扩展名识别
+	resp = postPreview(t, h, "track.GPX", func(w io.Writer) error {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><gpx version="1.1"><trk><trkseg>`)
+		for _, p := range points {
+			fmt.Fprintf(w, `<trkpt lat="%f" lon="%f"></trkpt>`, p.Latitude, p.Longitude)
+		}
+		_, err := fmt.Fprint(w, `</trkseg></trk></gpx>`)
+		return err
+	})
+	if len(resp.Raw) != len(points) || len(resp.Outliers) == 0 || resp.Skipped != 0 {
+		t.Errorf("GPX 预览结果不正确: %d %v %d", len(resp.Raw), resp.Outliers, resp.Skipped)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/preview", bytes.NewReader([]byte("not a form")))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("缺少上传文件时应返回 400，实际 %d", rec.Code)
+	}
+}
+
+// 以文件名 name 上传 write 写出的内容并解析 /preview 的响应
+func postPreview(t *testing.T, h http.Handler, name string, write func(io.Writer) error) previewResponse {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile("file", name)
+	if err := write(part); err != nil {
+		t.Fatal(err)
+	}
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/preview", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/preview 返回 %d: %s", rec.Code, rec.Body)
+	}
+	var resp previewResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	return resp
+}
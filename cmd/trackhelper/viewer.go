@@ -0,0 +1,104 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"os_project/trackstore"
+)
+
+// 查看器页面，不依赖外部资源，可离线使用
+//
+//go:embed web
+var webFS embed.FS
+
+// 上传文件的大小上限
+const maxPreviewSize = 64 << 20
+
+func viewerHandler() http.Handler {
+	sub, err := fs.Sub(webFS, "web")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServerFS(sub)
+}
+
+type previewResponse struct {
+	Raw        [][2]float64          `json:"raw"` // 通过校验、清洗前的点，与 Cleaned 一一对应
+	Cleaned    [][2]float64          `json:"cleaned"`
+	Outliers   []int                 `json:"outliers"` // 位置被修正的点的下标
+	Validation trackstore.Validation `json:"validation"`
+	Skipped    int                   `json:"skipped"`    // 经纬度无法解析而跳过的行
+	BadFields  int                   `json:"bad_fields"` // 无法解析而留空的单元格
+}
+
+// 校验并清洗上传的 XLSX 文件但不写入存储，返回原始点、清洗结果与异常点，供查看器对比
+func (s *server) handlePreview(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxPreviewSize)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("读取上传文件失败: %v", err))
+		return
+	}
+	defer file.Close()
+
+	// ReadTrack 只接受文件路径，按扩展名选择格式，临时文件沿用上传文件的扩展名
+	ext := ".xlsx"
+	if strings.EqualFold(filepath.Ext(header.Filename), ".gpx") {
+		ext = ".gpx"
+	}
+	tmp, err := os.CreateTemp("", "trackhelper-*"+ext)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = io.Copy(tmp, file)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	points, err := trackstore.ReadTrack(tmp.Name())
+	var readErr *trackstore.ReadError
+	if err != nil && !errors.As(err, &readErr) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	cleaned, validation, err := trackstore.Clean(points, s.opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp := previewResponse{
+		Raw:        make([][2]float64, len(cleaned)),
+		Cleaned:    make([][2]float64, len(cleaned)),
+		Outliers:   []int{},
+		Validation: validation,
+	}
+	if readErr != nil {
+		resp.Skipped, resp.BadFields = readErr.Skipped, readErr.BadFields
+	}
+	for i, p := range cleaned {
+		resp.Raw[i] = [2]float64{p.Longitude, p.Latitude}
+		if p.Original != nil {
+			resp.Raw[i] = [2]float64{p.Original.Longitude, p.Original.Latitude}
+		}
+		resp.Cleaned[i] = [2]float64{p.Longitude, p.Latitude}
+		if p.Flag != trackstore.FlagOriginal {
+			resp.Outliers = append(resp.Outliers, i)
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
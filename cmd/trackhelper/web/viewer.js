@@ -0,0 +1,370 @@
+// TrackHelper 离线轨迹查看器：Web 墨卡托投影画布，支持拖动、滚轮缩放，
+// 显示存储中的轨迹、数据块外包矩形，以及上传 XLSX 后原始点、清洗结果与异常点的对比。
+"use strict";
+
+const canvas = document.getElementById("map");
+const ctx = canvas.getContext("2d");
+const statusEl = document.getElementById("status");
+const legendEl = document.getElementById("legend");
+const tooltipEl = document.getElementById("tooltip");
+
+const MAX_LAT = 85.05112878;
+const EARTH_CIRCUMFERENCE = 40075016.686;
+const QUERY_LIMIT = 50000;
+
+const layers = {};
+document.querySelectorAll("[data-layer]").forEach((input) => {
+  layers[input.dataset.layer] = input.checked;
+  input.addEventListener("change", () => {
+    layers[input.dataset.layer] = input.checked;
+    draw();
+  });
+});
+
+// 视图：中心点的墨卡托归一化坐标，scale 为整个世界的像素宽度
+const view = { cx: 0.5, cy: 0.5, scale: 512 };
+let chunks = [];
+let tracks = []; // [{trajectory, points: [{x, y, lon, lat, task, time}]}]
+let truncated = false;
+let preview = null; // {raw, cleaned, outliers}
+
+function mercator(lon, lat) {
+  lat = Math.max(-MAX_LAT, Math.min(MAX_LAT, lat));
+  const y = Math.log(Math.tan(Math.PI / 4 + (lat * Math.PI) / 360));
+  return [(lon + 180) / 360, (1 - y / Math.PI) / 2];
+}
+
+function inverseMercator(x, y) {
+  const lat = (Math.atan(Math.sinh(Math.PI * (1 - 2 * y))) * 180) / Math.PI;
+  return [x * 360 - 180, lat];
+}
+
+function toScreen(x, y) {
+  return [(x - view.cx) * view.scale + canvas.width / 2, (y - view.cy) * view.scale + canvas.height / 2];
+}
+
+function toWorld(sx, sy) {
+  return [(sx - canvas.width / 2) / view.scale + view.cx, (sy - canvas.height / 2) / view.scale + view.cy];
+}
+
+// 按轨迹名计算固定的颜色
+function colorOf(name) {
+  let h = 0;
+  for (const c of name) h = (h * 31 + c.charCodeAt(0)) % 360;
+  return `hsl(${h}, 70%, 42%)`;
+}
+
+function fitBounds(minLon, minLat, maxLon, maxLat) {
+  const [x1, y1] = mercator(minLon, maxLat);
+  const [x2, y2] = mercator(maxLon, minLat);
+  view.cx = (x1 + x2) / 2;
+  view.cy = (y1 + y2) / 2;
+  const span = Math.max(x2 - x1, y2 - y1, 1e-7);
+  view.scale = (0.8 * Math.min(canvas.width, canvas.height)) / span;
+}
+
+async function getJSON(url) {
+  const resp = await fetch(url);
+  const body = await resp.json();
+  if (!resp.ok) throw new Error(body.error || resp.statusText);
+  return body;
+}
+
+async function loadStore() {
+  const stats = await getJSON("stats");
+  if (stats.chunks > 0) fitBounds(...stats.bbox);
+  chunks = (await getJSON("chunks")).map((c) => {
+    const [x1, y1] = mercator(c.bbox[0], c.bbox[3]);
+    const [x2, y2] = mercator(c.bbox[2], c.bbox[1]);
+    return { ...c, x1, y1, x2, y2 };
+  });
+  await loadPoints();
+}
+
+// 只加载当前视野内的点
+async function loadPoints() {
+  const [minLon, maxLat] = inverseMercator(...toWorld(0, 0));
+  const [maxLon, minLat] = inverseMercator(...toWorld(canvas.width, canvas.height));
+  const bbox = [minLon, minLat, maxLon, maxLat].map((v) => v.toFixed(7)).join(",");
+  const result = await getJSON(`query?bbox=${bbox}&limit=${QUERY_LIMIT}`);
+
+  tracks = [];
+  let current = null;
+  for (const f of result.features) {
+    const [lon, lat] = f.geometry.coordinates;
+    const [x, y] = mercator(lon, lat);
+    const p = { x, y, lon, lat, task: f.properties.task, time: f.properties.time, flag: f.properties.flag };
+    if (!current || current.trajectory !== f.properties.trajectory) {
+      current = { trajectory: f.properties.trajectory, points: [] };
+      tracks.push(current);
+    }
+    current.points.push(p);
+  }
+  truncated = !!result.truncated;
+  updateLegend();
+  draw();
+}
+
+let reloadTimer = 0;
+function scheduleReload() {
+  clearTimeout(reloadTimer);
+  reloadTimer = setTimeout(() => loadPoints().catch(showError), 250);
+}
+
+function updateLegend() {
+  const names = [...new Set(tracks.map((t) => t.trajectory))];
+  legendEl.innerHTML = "<strong>轨迹</strong>";
+  for (const name of names) {
+    const row = document.createElement("div");
+    const swatch = document.createElement("span");
+    swatch.className = "swatch";
+    swatch.style.background = colorOf(name);
+    row.append(swatch, name || "(未命名)");
+    legendEl.append(row);
+  }
+  if (preview) {
+    legendEl.insertAdjacentHTML(
+      "beforeend",
+      '<div><span class="swatch" style="background:#999"></span>原始点</div>' +
+        '<div><span class="swatch" style="background:#1565c0"></span>清洗后</div>' +
+        '<div><span class="swatch" style="background:#d32f2f"></span>异常点</div>'
+    );
+  }
+}
+
+function drawPolyline(points, color, width) {
+  ctx.strokeStyle = color;
+  ctx.lineWidth = width;
+  ctx.beginPath();
+  points.forEach((p, i) => {
+    const [sx, sy] = toScreen(p.x, p.y);
+    if (i === 0) ctx.moveTo(sx, sy);
+    else ctx.lineTo(sx, sy);
+  });
+  ctx.stroke();
+}
+
+function drawDots(points, color, radius) {
+  ctx.fillStyle = color;
+  for (const p of points) {
+    const [sx, sy] = toScreen(p.x, p.y);
+    ctx.beginPath();
+    ctx.arc(sx, sy, radius, 0, 2 * Math.PI);
+    ctx.fill();
+  }
+}
+
+// 经纬网代替底图，间隔随缩放选取
+function drawGraticule() {
+  const [minLon, maxLat] = inverseMercator(...toWorld(0, 0));
+  const [maxLon, minLat] = inverseMercator(...toWorld(canvas.width, canvas.height));
+  const target = (maxLon - minLon) / 6;
+  const steps = [30, 10, 5, 2, 1, 0.5, 0.2, 0.1, 0.05, 0.02, 0.01, 0.005, 0.002, 0.001, 0.0005, 0.0002, 0.0001];
+  const step = steps.find((s) => s <= target) || steps[steps.length - 1];
+  const digits = Math.max(0, -Math.floor(Math.log10(step)));
+
+  ctx.strokeStyle = "#ddd";
+  ctx.fillStyle = "#888";
+  ctx.lineWidth = 1;
+  for (let lon = Math.ceil(minLon / step) * step; lon <= maxLon; lon += step) {
+    const [sx] = toScreen(mercator(lon, 0)[0], 0);
+    ctx.beginPath();
+    ctx.moveTo(sx, 0);
+    ctx.lineTo(sx, canvas.height);
+    ctx.stroke();
+    ctx.fillText(lon.toFixed(digits), sx + 2, canvas.height - 4);
+  }
+  for (let lat = Math.ceil(minLat / step) * step; lat <= maxLat; lat += step) {
+    const [, sy] = toScreen(0, mercator(0, lat)[1]);
+    ctx.beginPath();
+    ctx.moveTo(0, sy);
+    ctx.lineTo(canvas.width, sy);
+    ctx.stroke();
+    ctx.fillText(lat.toFixed(digits), canvas.width - 60, sy - 2);
+  }
+}
+
+function drawScaleBar() {
+  const [, lat] = inverseMercator(view.cx, view.cy);
+  const metersPerPixel = (EARTH_CIRCUMFERENCE * Math.cos((lat * Math.PI) / 180)) / view.scale;
+  let meters = Math.pow(10, Math.floor(Math.log10(metersPerPixel * 120)));
+  if (meters / metersPerPixel < 50) meters *= 5;
+  else if (meters / metersPerPixel < 80) meters *= 2;
+  const width = meters / metersPerPixel;
+  const x = canvas.width - width - 20;
+  const y = canvas.height - 24;
+  ctx.strokeStyle = "#333";
+  ctx.lineWidth = 2;
+  ctx.beginPath();
+  ctx.moveTo(x, y - 5);
+  ctx.lineTo(x, y);
+  ctx.lineTo(x + width, y);
+  ctx.lineTo(x + width, y - 5);
+  ctx.stroke();
+  ctx.fillStyle = "#333";
+  ctx.fillText(meters >= 1000 ? `${meters / 1000} km` : `${meters} m`, x, y - 8);
+}
+
+function draw() {
+  ctx.clearRect(0, 0, canvas.width, canvas.height);
+  drawGraticule();
+
+  if (layers.envelopes) {
+    ctx.lineWidth = 1;
+    for (const c of chunks) {
+      const [sx1, sy1] = toScreen(c.x1, c.y1);
+      const [sx2, sy2] = toScreen(c.x2, c.y2);
+      if (sx2 < 0 || sy2 < 0 || sx1 > canvas.width || sy1 > canvas.height) continue;
+      ctx.strokeStyle = colorOf(c.trajectory);
+      ctx.globalAlpha = 0.35;
+      ctx.strokeRect(sx1, sy1, Math.max(sx2 - sx1, 1), Math.max(sy2 - sy1, 1));
+      ctx.globalAlpha = 1;
+    }
+  }
+
+  for (const t of tracks) {
+    if (layers.tracks) drawPolyline(t.points, colorOf(t.trajectory), 2);
+    if (layers.points) drawDots(t.points, colorOf(t.trajectory), 2.5);
+    // 存储中被清洗修改过的点
+    if (layers.outliers) drawDots(t.points.filter((p) => p.flag), "#d32f2f", 3);
+  }
+
+  if (preview) {
+    if (layers.raw) {
+      drawPolyline(preview.raw, "#999", 1);
+      drawDots(preview.raw, "#999", 2);
+    }
+    if (layers.cleaned) drawPolyline(preview.cleaned, "#1565c0", 2);
+    if (layers.outliers) {
+      drawDots(preview.outliers.map((i) => preview.raw[i]), "#d32f2f", 4);
+      drawDots(preview.outliers.map((i) => preview.cleaned[i]), "#1565c0", 3);
+    }
+  }
+  drawScaleBar();
+
+  const count = tracks.reduce((n, t) => n + t.points.length, 0);
+  let text = `视野内 ${count} 个点，${chunks.length} 个数据块`;
+  if (truncated) text += `（超过 ${QUERY_LIMIT} 个点，已截断，请放大）`;
+  if (preview) text += `；对比数据 ${preview.raw.length} 个点，异常点 ${preview.outliers.length} 个`;
+  statusEl.textContent = text;
+}
+
+function showError(err) {
+  statusEl.textContent = `错误: ${err.message}`;
+}
+
+function resize() {
+  canvas.width = window.innerWidth;
+  canvas.height = window.innerHeight;
+  draw();
+}
+
+// 拖动平移
+let drag = null;
+canvas.addEventListener("mousedown", (e) => {
+  drag = { x: e.clientX, y: e.clientY, cx: view.cx, cy: view.cy };
+  canvas.classList.add("dragging");
+});
+window.addEventListener("mouseup", () => {
+  if (!drag) return;
+  drag = null;
+  canvas.classList.remove("dragging");
+  scheduleReload();
+});
+canvas.addEventListener("mousemove", (e) => {
+  if (drag) {
+    view.cx = drag.cx - (e.clientX - drag.x) / view.scale;
+    view.cy = drag.cy - (e.clientY - drag.y) / view.scale;
+    draw();
+    return;
+  }
+  showTooltip(e);
+});
+
+// 以鼠标位置为中心缩放
+canvas.addEventListener(
+  "wheel",
+  (e) => {
+    e.preventDefault();
+    const [wx, wy] = toWorld(e.offsetX, e.offsetY);
+    const factor = Math.exp(-e.deltaY * 0.002);
+    view.scale = Math.min(Math.max(view.scale * factor, 256), 2 ** 30);
+    view.cx = wx - (e.offsetX - canvas.width / 2) / view.scale;
+    view.cy = wy - (e.offsetY - canvas.height / 2) / view.scale;
+    draw();
+    scheduleReload();
+  },
+  { passive: false }
+);
+
+// 鼠标附近的点
+function showTooltip(e) {
+  let best = null;
+  let bestDist = 8;
+  for (const t of tracks) {
+    for (const p of t.points) {
+      const [sx, sy] = toScreen(p.x, p.y);
+      const d = Math.hypot(sx - e.offsetX, sy - e.offsetY);
+      if (d < bestDist) {
+        best = { ...p, trajectory: t.trajectory };
+        bestDist = d;
+      }
+    }
+  }
+  if (!best) {
+    tooltipEl.style.display = "none";
+    return;
+  }
+  let text = `轨迹 ${best.trajectory || "(未命名)"}  数据块 ${best.task}\n${best.lon.toFixed(6)}, ${best.lat.toFixed(6)}`;
+  if (best.time) text += `\n${best.time}`;
+  if (best.flag) text += `\n${best.flag}`;
+  tooltipEl.textContent = text;
+  tooltipEl.style.left = `${e.clientX + 12}px`;
+  tooltipEl.style.top = `${e.clientY + 12}px`;
+  tooltipEl.style.display = "block";
+}
+
+// 上传原始 XLSX，由服务端按存储时的参数清洗后对比
+document.getElementById("source").addEventListener("change", async (e) => {
+  const file = e.target.files[0];
+  if (!file) return;
+  const form = new FormData();
+  form.append("file", file);
+  try {
+    const resp = await fetch("preview", { method: "POST", body: form });
+    const body = await resp.json();
+    if (!resp.ok) throw new Error(body.error || resp.statusText);
+    const toPoints = (coords) =>
+      coords.map(([lon, lat]) => {
+        const [x, y] = mercator(lon, lat);
+        return { x, y };
+      });
+    preview = { raw: toPoints(body.raw), cleaned: toPoints(body.cleaned), outliers: body.outliers };
+    if (body.raw.length > 0) {
+      const b = [Infinity, Infinity, -Infinity, -Infinity];
+      for (const [lon, lat] of body.raw) {
+        b[0] = Math.min(b[0], lon);
+        b[1] = Math.min(b[1], lat);
+        b[2] = Math.max(b[2], lon);
+        b[3] = Math.max(b[3], lat);
+      }
+      fitBounds(...b);
+    }
+    updateLegend();
+    draw();
+    scheduleReload();
+  } catch (err) {
+    showError(err);
+  }
+});
+
+document.getElementById("fit").addEventListener("click", () => {
+  loadStore().catch(showError);
+});
+
+window.addEventListener("resize", () => {
+  resize();
+  scheduleReload();
+});
+resize();
+loadStore().catch(showError);
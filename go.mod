@@ -4,6 +4,13 @@ go 1.23.0
 
 toolchain go1.23.10
 
+require (
+	github.com/tealeg/xlsx v1.0.5
+	gonum.org/v1/plot v0.16.0
+	google.golang.org/grpc v1.67.1
+	google.golang.org/protobuf v1.36.5
+)
+
 require (
 	codeberg.org/go-fonts/liberation v0.5.0 // indirect
 	codeberg.org/go-latex/latex v0.1.0 // indirect
@@ -15,8 +22,9 @@ require (
 	github.com/extrame/xls v0.0.1 // indirect
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/tealeg/xlsx v1.0.5 // indirect
 	golang.org/x/image v0.25.0 // indirect
+	golang.org/x/net v0.28.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.23.0 // indirect
-	gonum.org/v1/plot v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240814211410-ddb44dafa142 // indirect
 )
@@ -0,0 +1,247 @@
+// Package trackrpc 基于 trackstore 的 gRPC 写入与查询服务。
+// trackhelper.pb.go 与 trackhelper_grpc.pb.go 由 trackhelper.proto 生成，不要手动修改。
+package trackrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative trackhelper.proto
+
+import (
+	"errors"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"os_project/trackstore"
+)
+
+// DefaultMaxIngestPoints 单次 Ingest 默认最多缓存的点数
+const DefaultMaxIngestPoints = 1000000
+
+// Server 在一个已打开的 Store 上实现 TrackService
+type Server struct {
+	UnimplementedTrackServiceServer
+	store     *trackstore.Store
+	maxIngest int // 单次 Ingest 最多缓存的点数
+}
+
+// NewServer maxIngest 为单次 Ingest 最多接收的点数，不大于 0 时使用 DefaultMaxIngestPoints
+func NewServer(store *trackstore.Store, maxIngest int) *Server {
+	if maxIngest <= 0 {
+		maxIngest = DefaultMaxIngestPoints
+	}
+	return &Server{store: store, maxIngest: maxIngest}
+}
+
+// Ingest 读完整个流后按轨迹首次出现的顺序逐条调用 Store.Append，
+// 与 STORE 使用同一套划分、清洗、写入流程。
+// 个别数据块失败时仍返回报告，失败原因在 errors 中；命中 reject 校验规则时返回 InvalidArgument。
+// 流中的点数超过 maxIngest 时不写入任何数据，返回 ResourceExhausted。
+func (s *Server) Ingest(stream TrackService_IngestServer) error {
+	var order []string
+	tracks := make(map[string][]trackstore.Point)
+	trajectory := ""
+	received := 0
+	for {
+		p, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if p.Trajectory != "" {
+			trajectory = p.Trajectory
+		}
+		if trajectory == "" {
+			return status.Error(codes.InvalidArgument, "第一个点需要指定所属轨迹")
+		}
+		if received++; received > s.maxIngest {
+			return status.Errorf(codes.ResourceExhausted, "单次写入最多 %d 个点，请分批发送", s.maxIngest)
+		}
+		if _, ok := tracks[trajectory]; !ok {
+			order = append(order, trajectory)
+		}
+		tracks[trajectory] = append(tracks[trajectory], fromProto(p))
+	}
+
+	ctx := stream.Context()
+	total := &IngestReport{}
+	for _, name := range order {
+		report, err := s.store.Append(ctx, name, tracks[name])
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		var verr *trackstore.ValidationError
+		if errors.As(err, &verr) {
+			return status.Errorf(codes.InvalidArgument, "轨迹 %s: %v", name, err)
+		}
+		if err != nil && report.Failed == 0 {
+			return status.Errorf(codes.Internal, "写入轨迹 %s 失败: %v", name, err)
+		}
+		total.Chunks += int32(report.Chunks)
+		total.Succeeded += int32(report.Succeeded)
+		total.Failed += int32(report.Failed)
+		total.Skipped += int32(report.Skipped)
+		total.PointsIn += int32(report.PointsIn)
+		total.PointsOut += int32(report.PointsOut)
+		total.Fixed += int32(report.Fixed)
+		for _, e := range report.Errors {
+			total.Errors = append(total.Errors, e.Error())
+		}
+	}
+	return stream.SendAndClose(total)
+}
+
+// Query 按 QueryChunks 的顺序逐点发送
+func (s *Server) Query(req *QueryRequest, stream TrackService_QueryServer) error {
+	q, err := toQuery(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ctx := stream.Context()
+	var sent int64
+	for chunk, err := range s.store.QueryChunks(ctx, q) {
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return status.FromContextError(ctxErr).Err()
+			}
+			return status.Error(codes.Internal, err.Error())
+		}
+		for _, p := range chunk.Points {
+			if req.Limit > 0 && sent == req.Limit {
+				return nil
+			}
+			msg := toProto(p)
+			msg.Trajectory = chunk.Meta.Trajectory
+			msg.Task = int32(chunk.Meta.TaskIdx)
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+			sent++
+		}
+	}
+	return nil
+}
+
+func fromProto(p *Point) trackstore.Point {
+	pt := trackstore.Point{Longitude: p.Longitude, Latitude: p.Latitude}
+	if p.Time != nil {
+		pt.Time = p.Time.AsTime()
+	}
+	if p.Altitude != nil {
+		pt.Altitude, pt.HasAltitude = *p.Altitude, true
+	}
+	// PointFlag 的取值与 trackstore.PointFlag 一致
+	pt.Flag = trackstore.PointFlag(p.Flag)
+	pt.Attrs = fromProtoAttrs(p.Attributes)
+	if p.Original != nil {
+		original := fromProto(p.Original)
+		pt.Original = &original
+	}
+	return pt
+}
+
+// 未设置的字段视为没有记录，没有任何属性时返回 nil
+func fromProtoAttrs(a *Attributes) *trackstore.Attributes {
+	if a == nil {
+		return nil
+	}
+	attrs := &trackstore.Attributes{Extra: a.Extra}
+	if a.Speed != nil {
+		attrs.Speed, attrs.Fields = *a.Speed, attrs.Fields|trackstore.AttrSpeed
+	}
+	if a.Heading != nil {
+		attrs.Heading, attrs.Fields = *a.Heading, attrs.Fields|trackstore.AttrHeading
+	}
+	if a.Hdop != nil {
+		attrs.HDOP, attrs.Fields = *a.Hdop, attrs.Fields|trackstore.AttrHDOP
+	}
+	if a.Satellites != nil {
+		attrs.Satellites, attrs.Fields = int(*a.Satellites), attrs.Fields|trackstore.AttrSatellites
+	}
+	if a.Accuracy != nil {
+		attrs.Accuracy, attrs.Fields = *a.Accuracy, attrs.Fields|trackstore.AttrAccuracy
+	}
+	if attrs.Fields == 0 && len(attrs.Extra) == 0 {
+		return nil
+	}
+	return attrs
+}
+
+func toProto(p trackstore.Point) *Point {
+	msg := &Point{Longitude: p.Longitude, Latitude: p.Latitude}
+	if !p.Time.IsZero() {
+		msg.Time = timestamppb.New(p.Time)
+	}
+	if p.HasAltitude {
+		msg.Altitude = proto.Float64(p.Altitude)
+	}
+	msg.Flag = PointFlag(p.Flag)
+	msg.Attributes = toProtoAttrs(p.Attrs)
+	if p.Original != nil {
+		msg.Original = toProto(*p.Original)
+	}
+	return msg
+}
+
+// 只设置记录了的字段，a 为 nil 时返回 nil
+func toProtoAttrs(a *trackstore.Attributes) *Attributes {
+	if a == nil {
+		return nil
+	}
+	msg := &Attributes{Extra: a.Extra}
+	if a.Has(trackstore.AttrSpeed) {
+		msg.Speed = proto.Float64(a.Speed)
+	}
+	if a.Has(trackstore.AttrHeading) {
+		msg.Heading = proto.Float64(a.Heading)
+	}
+	if a.Has(trackstore.AttrHDOP) {
+		msg.Hdop = proto.Float64(a.HDOP)
+	}
+	if a.Has(trackstore.AttrSatellites) {
+		msg.Satellites = proto.Int32(int32(a.Satellites))
+	}
+	if a.Has(trackstore.AttrAccuracy) {
+		msg.Accuracy = proto.Float64(a.Accuracy)
+	}
+	return msg
+}
+
+func toQuery(req *QueryRequest) (trackstore.Query, error) {
+	q := trackstore.Query{Trajectory: req.Trajectory}
+	if b := req.Bbox; b != nil {
+		q.BBox = &trackstore.BBox{
+			Min: trackstore.Point{Longitude: b.MinLongitude, Latitude: b.MinLatitude},
+			Max: trackstore.Point{Longitude: b.MaxLongitude, Latitude: b.MaxLatitude},
+		}
+	}
+	if c := req.Radius; c != nil {
+		if c.Radius <= 0 {
+			return q, errors.New("半径必须大于 0")
+		}
+		q.Radius = &trackstore.Circle{
+			Center: trackstore.Point{Longitude: c.Longitude, Latitude: c.Latitude},
+			Radius: c.Radius,
+		}
+	}
+	if req.Polygon != "" {
+		pg, err := trackstore.ParsePolygon(req.Polygon)
+		if err != nil {
+			return q, err
+		}
+		q.Polygon = &pg
+	}
+	if req.Since != nil {
+		q.Since = req.Since.AsTime()
+	}
+	if req.Until != nil {
+		q.Until = req.Until.AsTime()
+	}
+	return q, nil
+}
+
+var _ TrackServiceServer = (*Server)(nil)
@@ -0,0 +1,273 @@
+package trackrpc
+
+import (
+	"context"
+	"io"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"os_project/trackstore"
+)
+
+// 在内存连接上启动服务，返回客户端
+func newTestClient(t *testing.T, store *trackstore.Store) TrackServiceClient {
+	return newLimitedClient(t, store, 0)
+}
+
+// 与 newTestClient 相同，单次 Ingest 最多接收 maxIngest 个点
+func newLimitedClient(t *testing.T, store *trackstore.Store, maxIngest int) TrackServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	RegisterTrackServiceServer(srv, NewServer(store, maxIngest))
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("连接服务失败: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewTrackServiceClient(conn)
+}
+
+func TestIngestQuery(t *testing.T) {
+	store, err := trackstore.Open(t.TempDir(), trackstore.DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+	client := newTestClient(t, store)
+	ctx := context.Background()
+
+	ingest, err := client.Ingest(ctx)
+	if err != nil {
+		t.Fatalf("Ingest 失败: %v", err)
+	}
+	start := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < 50; i++ {
+		p := &Point{Longitude: 120.0 + float64(i)*0.0003, Latitude: 30.0, Time: timestamppb.New(start.Add(time.Duration(i) * time.Second))}
+		if i == 0 {
+			p.Trajectory = "a"
+		}
+		if err := ingest.Send(p); err != nil {
+			t.Fatalf("发送失败: %v", err)
+		}
+	}
+	for i := 0; i < 30; i++ {
+		if err := ingest.Send(&Point{Longitude: 121.0 + float64(i)*0.0003, Latitude: 30.0, Trajectory: "b"}); err != nil {
+			t.Fatalf("发送失败: %v", err)
+		}
+	}
+	report, err := ingest.CloseAndRecv()
+	if err != nil {
+		t.Fatalf("Ingest 失败: %v", err)
+	}
+	if report.PointsOut != 80 || report.Failed != 0 {
+		t.Errorf("报告不正确: %v", report)
+	}
+
+	count := func(req *QueryRequest) (int, map[string]int) {
+		stream, err := client.Query(ctx, req)
+		if err != nil {
+			t.Fatalf("Query 失败: %v", err)
+		}
+		n, byTrajectory := 0, make(map[string]int)
+		for {
+			p, err := stream.Recv()
+			if err == io.EOF {
+				return n, byTrajectory
+			}
+			if err != nil {
+				t.Fatalf("Query 失败: %v", err)
+			}
+			n++
+			byTrajectory[p.Trajectory]++
+		}
+	}
+
+	if n, byTrajectory := count(&QueryRequest{}); n != 80 || byTrajectory["a"] != 50 || byTrajectory["b"] != 30 {
+		t.Errorf("全部查询结果不正确: %d %v", n, byTrajectory)
+	}
+	if n, _ := count(&QueryRequest{Trajectory: "b", Limit: 10}); n != 10 {
+		t.Errorf("limit 未生效: %d", n)
+	}
+	if n, _ := count(&QueryRequest{Since: timestamppb.New(start.Add(40 * time.Second))}); n != 10 {
+		t.Errorf("时间窗口查询结果不正确: %d", n)
+	}
+	if n, _ := count(&QueryRequest{Radius: &Circle{Longitude: 121.0, Latitude: 30.0, Radius: 100}}); n != 4 {
+		t.Errorf("半径查询结果不正确: %d", n)
+	}
+
+	// 服务端流的错误在第一次 Recv 时返回
+	stream, err := client.Query(ctx, &QueryRequest{Polygon: "LINESTRING (0 0, 1 1)"})
+	if err != nil {
+		t.Fatalf("Query 失败: %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("无效多边形应返回 InvalidArgument，实际 %v", err)
+	}
+}
+
+func TestIngestLimit(t *testing.T) {
+	store, err := trackstore.Open(t.TempDir(), trackstore.DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+	client := newLimitedClient(t, store, 20)
+
+	send := func(n int) (*IngestReport, error) {
+		ingest, err := client.Ingest(context.Background())
+		if err != nil {
+			t.Fatalf("Ingest 失败: %v", err)
+		}
+		for i := 0; i < n; i++ {
+			// 服务端提前返回后 Send 得到 io.EOF，错误由 CloseAndRecv 返回
+			if err := ingest.Send(&Point{Longitude: 120.0 + float64(i)*0.0003, Latitude: 30.0, Trajectory: "a"}); err == io.EOF {
+				break
+			}
+		}
+		return ingest.CloseAndRecv()
+	}
+
+	if _, err := send(30); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("超过上限应返回 ResourceExhausted，实际 %v", err)
+	}
+	if n := len(store.Index().Chunks); n != 0 {
+		t.Errorf("超过上限时不应写入数据块，实际写入 %d 个", n)
+	}
+	if report, err := send(20); err != nil || report.PointsOut != 20 {
+		t.Errorf("未超过上限时应正常写入: %v %v", report, err)
+	}
+}
+
+// 发送 points 并按顺序取回全部点
+func roundTrip(t *testing.T, client TrackServiceClient, points []*Point) []*Point {
+	t.Helper()
+	ctx := context.Background()
+	ingest, err := client.Ingest(ctx)
+	if err != nil {
+		t.Fatalf("Ingest 失败: %v", err)
+	}
+	for _, p := range points {
+		if err := ingest.Send(p); err != nil {
+			t.Fatalf("发送失败: %v", err)
+		}
+	}
+	if _, err := ingest.CloseAndRecv(); err != nil {
+		t.Fatalf("Ingest 失败: %v", err)
+	}
+
+	stream, err := client.Query(ctx, &QueryRequest{})
+	if err != nil {
+		t.Fatalf("Query 失败: %v", err)
+	}
+	var got []*Point
+	for {
+		p, err := stream.Recv()
+		if err == io.EOF {
+			return got
+		}
+		if err != nil {
+			t.Fatalf("Query 失败: %v", err)
+		}
+		got = append(got, p)
+	}
+}
+
+func TestAltitudeRoundTrip(t *testing.T) {
+	opts := trackstore.DefaultOptions()
+	opts.MaxClimbRate = 50
+	store, err := trackstore.Open(t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+	client := newTestClient(t, store)
+
+	// 第 10 个点为高程尖刺，最后一个点没有海拔，第一个点海拔为 0
+	points := make([]*Point, 20)
+	for i := range points {
+		points[i] = &Point{Longitude: 120.0 + float64(i)*0.0003, Latitude: 30.0, Trajectory: "a", Altitude: proto.Float64(100)}
+	}
+	points[0].Altitude = proto.Float64(0)
+	points[10].Altitude = proto.Float64(400)
+	points[19].Altitude = nil
+
+	got := roundTrip(t, client, points)
+	if len(got) != len(points) {
+		t.Fatalf("期望 %d 个点，实际 %d 个", len(points), len(got))
+	}
+	if got[0].Altitude == nil || *got[0].Altitude != 0 || got[5].GetAltitude() != 100 {
+		t.Errorf("海拔应原样返回: %v %v", got[0], got[5])
+	}
+	if got[19].Altitude != nil {
+		t.Errorf("没有海拔的点不应返回海拔: %v", got[19])
+	}
+	if math.Abs(got[10].GetAltitude()-100) > 1e-9 {
+		t.Errorf("gRPC 写入的高程尖刺应被修正: %v", got[10])
+	}
+}
+
+func TestAttributesRoundTrip(t *testing.T) {
+	opts := trackstore.DefaultOptions()
+	opts.WeightHDOP = true
+	store, err := trackstore.Open(t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+	client := newTestClient(t, store)
+
+	// 第 10 个点偏离约 22 米，只有按 HDOP 收紧阈值时才会被修正
+	points := make([]*Point, 20)
+	for i := range points {
+		points[i] = &Point{Longitude: 120.0 + float64(i)*0.0003, Latitude: 30.0, Trajectory: "a", Attributes: &Attributes{
+			Speed:      proto.Float64(30),
+			Heading:    proto.Float64(90),
+			Satellites: proto.Int32(9),
+			Accuracy:   proto.Float64(3),
+			Extra:      map[string]string{"mode": "walk"},
+		}}
+	}
+	points[10].Latitude += 0.0002
+	points[10].Attributes = &Attributes{Hdop: proto.Float64(8), Extra: map[string]string{"mode": "walk"}}
+	points[19].Attributes = nil
+
+	got := roundTrip(t, client, points)
+	if len(got) != len(points) {
+		t.Fatalf("期望 %d 个点，实际 %d 个", len(points), len(got))
+	}
+	if !proto.Equal(got[5].Attributes, points[5].Attributes) || got[5].Flag != PointFlag_POINT_FLAG_ORIGINAL || got[5].Original != nil {
+		t.Errorf("附加属性应原样返回: %v", got[5])
+	}
+	if got[19].Attributes != nil {
+		t.Errorf("没有附加属性的点不应返回附加属性: %v", got[19])
+	}
+
+	p := got[10]
+	if p.Flag != PointFlag_POINT_FLAG_INTERPOLATED || math.Abs(p.Latitude-30) > 1e-9 {
+		t.Errorf("gRPC 写入的 HDOP 应参与异常检测: %v", p)
+	}
+	if p.Original == nil || p.Original.Latitude != points[10].Latitude || p.Original.GetAttributes().GetHdop() != 8 {
+		t.Errorf("应返回修正前的点: %v", p.Original)
+	}
+	if a := p.Attributes; a.GetHdop() != 8 || a.GetSpeed() != 30 || a.GetExtra()["mode"] != "walk" {
+		t.Errorf("修正后的点应保留 HDOP 与其他列并插值速度: %v", a)
+	}
+}
@@ -0,0 +1,106 @@
+package trackstore
+
+import (
+	"maps"
+	"math"
+	"strings"
+)
+
+// AttrField 已知的点属性，用作 Attributes.Fields 的位掩码
+type AttrField uint8
+
+const (
+	AttrSpeed      AttrField = 1 << iota // 设备记录的速度（米/秒）
+	AttrHeading                          // 航向（度，正北为 0，顺时针）
+	AttrHDOP                             // 水平精度因子
+	AttrSatellites                       // 参与定位的卫星数
+	AttrAccuracy                         // 设备报告的水平误差（米）
+)
+
+// Attributes 轨迹点的附加属性：定位设备记录的已知字段，以及其他按列名保存的原始值。
+// 写入后不再修改，多个 Point 可以共用同一个 *Attributes
+type Attributes struct {
+	Fields     AttrField // 记录了哪些已知字段
+	Speed      float64
+	Heading    float64
+	HDOP       float64
+	Satellites int
+	Accuracy   float64
+	Extra      map[string]string // 其他列，列名到单元格文本
+}
+
+// 深拷贝，a 为 nil 时返回 nil
+func (a *Attributes) clone() *Attributes {
+	if a == nil {
+		return nil
+	}
+	c := *a
+	c.Extra = maps.Clone(a.Extra)
+	return &c
+}
+
+// Has 是否记录了字段 f，a 为 nil 时返回 false
+func (a *Attributes) Has(f AttrField) bool {
+	return a != nil && a.Fields&f != 0
+}
+
+// HDOP 点的水平精度因子，没有记录时 ok 为 false
+func (p Point) HDOP() (hdop float64, ok bool) {
+	if !p.Attrs.Has(AttrHDOP) {
+		return 0, false
+	}
+	return p.Attrs.HDOP, true
+}
+
+// FixWeight 定位的可信程度，取值 (0, 1]：按 HorizontalError(p, uere) 换算的水平误差不超过 uere
+// （即 HDOP 不超过 1）或没有记录时为 1，否则为 uere 与水平误差之比
+func FixWeight(p Point, uere float64) float64 {
+	if sigma, ok := HorizontalError(p, uere); ok && sigma > uere {
+		return uere / sigma
+	}
+	return 1
+}
+
+// 插值点的属性：速度在前后两点间线性插值，航向沿较小的夹角插值；
+// HDOP、水平误差、卫星数与其他列描述的是该时刻的接收机状态，沿用被修正的点的记录
+func interpolateAttrs(prev, next, original *Attributes, t float64) *Attributes {
+	if prev == nil && next == nil && original == nil {
+		return nil
+	}
+	attrs := &Attributes{}
+	if original != nil {
+		*attrs = *original
+		attrs.Fields &^= AttrSpeed | AttrHeading
+	}
+	if prev.Has(AttrSpeed) && next.Has(AttrSpeed) {
+		attrs.Speed = prev.Speed + t*(next.Speed-prev.Speed)
+		attrs.Fields |= AttrSpeed
+	}
+	if prev.Has(AttrHeading) && next.Has(AttrHeading) {
+		delta := math.Mod(next.Heading-prev.Heading+540, 360) - 180
+		attrs.Heading = math.Mod(prev.Heading+t*delta+360, 360)
+		attrs.Fields |= AttrHeading
+	}
+	if attrs.Fields == 0 && len(attrs.Extra) == 0 {
+		return nil
+	}
+	return attrs
+}
+
+// 读取文件时识别的列名，不区分大小写
+var columnNames = map[string]string{
+	"lon": "lon", "lng": "lon", "longitude": "lon", "经度": "lon",
+	"lat": "lat", "latitude": "lat", "纬度": "lat",
+	"time": "time", "timestamp": "time", "时间": "time",
+	"alt": "alt", "altitude": "alt", "ele": "alt", "elevation": "alt", "海拔": "alt",
+	"speed": "speed", "速度": "speed",
+	"heading": "heading", "course": "heading", "bearing": "heading", "航向": "heading",
+	"sat": "sat", "sats": "sat", "satellites": "sat", "卫星数": "sat",
+	"hdop": "hdop", "精度因子": "hdop",
+	"accuracy": "accuracy", "hacc": "accuracy", "eph": "accuracy", "horizontal_accuracy": "accuracy", "定位精度": "accuracy",
+}
+
+// 按列名识别已知列，未识别的列返回空字符串
+func knownColumn(name string) string {
+	return columnNames[strings.ToLower(strings.TrimSpace(name))]
+}
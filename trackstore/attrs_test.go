@@ -0,0 +1,180 @@
+package trackstore
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+func TestInterpolateAttrs(t *testing.T) {
+	prev := &Attributes{Fields: AttrSpeed | AttrHeading, Speed: 2, Heading: 350}
+	next := &Attributes{Fields: AttrSpeed | AttrHeading | AttrHDOP, Speed: 4, Heading: 10, HDOP: 0.8}
+	original := &Attributes{Fields: AttrSpeed | AttrHDOP | AttrSatellites, Speed: 40, HDOP: 9, Satellites: 4, Extra: map[string]string{"mode": "walk"}}
+
+	got := interpolateAttrs(prev, next, original, 0.25)
+	if math.Abs(got.Speed-2.5) > 1e-9 || math.Abs(got.Heading-355) > 1e-9 {
+		t.Errorf("速度与航向插值不正确: %+v", got)
+	}
+	if !got.Has(AttrHDOP) || got.HDOP != 9 || got.Satellites != 4 || got.Extra["mode"] != "walk" {
+		t.Errorf("HDOP、卫星数与其他列应沿用原记录: %+v", got)
+	}
+	if original.Speed != 40 {
+		t.Errorf("不应修改原记录")
+	}
+
+	// 前后两点缺少速度时不插值，原记录中的速度作废
+	got = interpolateAttrs(nil, next, original, 0.5)
+	if got.Has(AttrSpeed) || got.Has(AttrHeading) {
+		t.Errorf("缺少端点时不应插值: %+v", got)
+	}
+	if interpolateAttrs(nil, nil, nil, 0.5) != nil {
+		t.Errorf("都没有属性时应返回 nil")
+	}
+}
+
+func TestFixWeight(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		attrs *Attributes
+		uere  float64
+		want  float64
+	}{
+		{"没有 HDOP", nil, DefaultUERE, 1},
+		{"HDOP 不超过 1", &Attributes{Fields: AttrHDOP, HDOP: 0.7}, DefaultUERE, 1},
+		{"HDOP 为 4", &Attributes{Fields: AttrHDOP, HDOP: 4}, DefaultUERE, 0.25},
+		{"HDOP 与 UERE 无关", &Attributes{Fields: AttrHDOP, HDOP: 4}, 2, 0.25},
+		{"优先按水平误差", &Attributes{Fields: AttrHDOP | AttrAccuracy, HDOP: 4, Accuracy: 10}, DefaultUERE, 0.5},
+		{"水平误差按 UERE 换算", &Attributes{Fields: AttrAccuracy, Accuracy: 10}, 2, 0.2},
+		{"水平误差不超过 UERE", &Attributes{Fields: AttrAccuracy, Accuracy: 10}, 20, 1},
+	} {
+		if w := FixWeight(Point{Attrs: tc.attrs}, tc.uere); w != tc.want {
+			t.Errorf("%s: 权重应为 %v，实际 %v", tc.name, tc.want, w)
+		}
+	}
+}
+
+func TestWeightHDOP(t *testing.T) {
+	// 第 10 个点偏离约 22 米、绕行约 15 米，速度变化不足以触发默认阈值，但该点 HDOP 很大
+	points := linePoints(20, 120.0)
+	points[10].Latitude += 0.0002
+	points[10].Attrs = &Attributes{Fields: AttrHDOP, HDOP: 8}
+
+	task := Data{Points: points, Start: 0, End: len(points) - 1}
+	if _, fixed := SpeedOutliner(task); fixed != 0 {
+		t.Fatalf("未启用 HDOP 加权时不应修正，实际修正 %d 个", fixed)
+	}
+	task.WeightHDOP = true
+	result, fixed := SpeedOutliner(task)
+	if fixed == 0 || result[10].Flag != FlagInterpolated {
+		t.Errorf("HDOP 很大的点应被修正: %+v", result[10])
+	}
+	if hdop, ok := result[10].HDOP(); !ok || hdop != 8 {
+		t.Errorf("修正后的点应保留 HDOP 记录: %v %v", hdop, ok)
+	}
+}
+
+func TestWeightHDOPUERE(t *testing.T) {
+	// 第 10 个点记录的水平误差为 15 米，偏离约 22 米、绕行约 15 米
+	points := linePoints(20, 120.0)
+	points[10].Latitude += 0.0002
+	points[10].Attrs = &Attributes{Fields: AttrAccuracy, Accuracy: 15}
+
+	task := Data{Points: points, Start: 0, End: len(points) - 1, WeightHDOP: true}
+	if _, fixed := SpeedOutliner(task); fixed != 1 {
+		t.Errorf("按默认 UERE 换算权重为 1/3，应修正该点，实际修正 %d 个", fixed)
+	}
+	task.Kalman.UERE = 15
+	if _, fixed := SpeedOutliner(task); fixed != 0 {
+		t.Errorf("水平误差不超过配置的 UERE 时不应收紧阈值，实际修正 %d 个", fixed)
+	}
+}
+
+func TestWeightHDOPCorner(t *testing.T) {
+	// 在第 10 个点直角转弯，经过该点比直接连接前后两点多走约 17 米，HDOP 为 2 时不应视为异常
+	points := linePoints(20, 120.0)
+	for i := 11; i < len(points); i++ {
+		points[i].Longitude = points[10].Longitude
+		points[i].Latitude = 30.0 + float64(i-10)*0.00026
+	}
+	points[10].Attrs = &Attributes{Fields: AttrHDOP, HDOP: 2}
+
+	task := Data{Points: points, Start: 0, End: len(points) - 1, WeightHDOP: true}
+	if result, fixed := SpeedOutliner(task); fixed != 0 {
+		t.Errorf("HDOP 为 2 的转弯点不应被修正，实际修正 %d 个: %+v", fixed, result[10])
+	}
+}
+
+func TestReadXLSXHeader(t *testing.T) {
+	file := xlsx.NewFile()
+	sheet, _ := file.AddSheet("track")
+	for _, values := range [][]string{
+		{"Latitude", "Longitude", "HDOP", "sats", "mode", "speed"},
+		{"30.0", "120.0", "1.2", "9", "walk", "1.5"},
+		{"30.0001", "120.0001", "", "", "", ""},
+		{"bad", "120.0002", "", "", "", ""},
+		{"30.0003", "120.0003", "x", "many", "run", "fast"},
+	} {
+		row := sheet.AddRow()
+		for _, v := range values {
+			row.AddCell().SetString(v)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "header.xlsx")
+	if err := file.Save(path); err != nil {
+		t.Fatalf("保存测试文件失败: %v", err)
+	}
+
+	// 经纬度无法解析的行跳过，可选列无法解析时只留空该字段
+	points, err := ReadXLSX(path)
+	var readErr *ReadError
+	if !errors.As(err, &readErr) || readErr.Skipped != 1 || readErr.BadFields != 3 {
+		t.Fatalf("应返回跳过 1 行、留空 3 个单元格的 ReadError，实际 %v", err)
+	}
+	if len(points) != 3 {
+		t.Fatalf("期望 3 个点，实际 %d 个", len(points))
+	}
+	p := points[0]
+	if p.Longitude != 120 || p.Latitude != 30 {
+		t.Errorf("应按列名读取经纬度: %+v", p)
+	}
+	a := p.Attrs
+	if !a.Has(AttrHDOP) || a.HDOP != 1.2 || a.Satellites != 9 || a.Speed != 1.5 || a.Has(AttrHeading) || a.Extra["mode"] != "walk" {
+		t.Errorf("附加属性不正确: %+v", a)
+	}
+	if points[1].Attrs != nil {
+		t.Errorf("没有属性的行 Attrs 应为 nil: %+v", points[1].Attrs)
+	}
+	if p := points[2]; p.Longitude != 120.0003 || p.Attrs.Has(AttrHDOP) || p.Attrs.Has(AttrSatellites) || p.Attrs.Has(AttrSpeed) || p.Attrs.Extra["mode"] != "run" {
+		t.Errorf("无法解析的单元格应留空，其余列照常读取: %+v %+v", p, p.Attrs)
+	}
+}
+
+func TestAttrsRoundTrip(t *testing.T) {
+	store, err := Open(t.TempDir(), DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+
+	points := linePoints(20, 120.0)
+	for i := range points {
+		points[i].Attrs = &Attributes{Fields: AttrSatellites, Satellites: i, Extra: map[string]string{"id": "x"}}
+	}
+	if _, err := store.Append(context.Background(), "a", points); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	i := 0
+	for p, err := range store.Query(context.Background(), Query{}) {
+		if err != nil {
+			t.Fatalf("查询失败: %v", err)
+		}
+		if !p.Attrs.Has(AttrSatellites) || p.Attrs.Satellites != i || p.Attrs.Extra["id"] != "x" {
+			t.Errorf("第 %d 个点的属性未保存: %+v", i, p.Attrs)
+		}
+		i++
+	}
+}
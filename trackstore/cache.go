@@ -0,0 +1,67 @@
+package trackstore
+
+import (
+	"container/list"
+	"sync"
+)
+
+// 已解码数据块的 LRU 缓存，按数据块个数限制容量。
+// 缓存中的切片被多个调用方共享，不可修改。
+type chunkCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // 队首为最近使用
+	items    map[int]*list.Element
+	hits     uint64
+	misses   uint64
+}
+
+type cacheEntry struct {
+	taskIdx int
+	points  []Point
+}
+
+func newChunkCache(capacity int) *chunkCache {
+	return &chunkCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[int]*list.Element),
+	}
+}
+
+func (c *chunkCache) get(taskIdx int) ([]Point, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[taskIdx]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).points, true
+}
+
+func (c *chunkCache) put(taskIdx int, points []Point) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[taskIdx]; ok {
+		elem.Value.(*cacheEntry).points = points
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[taskIdx] = c.order.PushFront(&cacheEntry{taskIdx, points})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).taskIdx)
+	}
+}
+
+func (c *chunkCache) stats() (hits, misses uint64, size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses, c.order.Len()
+}
@@ -0,0 +1,130 @@
+package trackstore
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestChunkCache(t *testing.T) {
+	c := newChunkCache(2)
+	c.put(1, []Point{{Longitude: 1}})
+	c.put(2, []Point{{Longitude: 2}})
+	c.get(1)
+	c.put(3, []Point{{Longitude: 3}})
+
+	// 2 最久未使用，应被淘汰
+	if _, ok := c.get(2); ok {
+		t.Errorf("数据块 2 应被淘汰")
+	}
+	for _, idx := range []int{1, 3} {
+		if points, ok := c.get(idx); !ok || points[0].Longitude != float64(idx) {
+			t.Errorf("数据块 %d 应在缓存中", idx)
+		}
+	}
+	if hits, misses, size := c.stats(); hits != 3 || misses != 1 || size != 2 {
+		t.Errorf("缓存统计不正确: hits=%d misses=%d size=%d", hits, misses, size)
+	}
+}
+
+// 启用缓存后并发查询的结果应与不启用时相同，缓存中的数据块不能被查询修改
+func TestStoreCacheConcurrentQuery(t *testing.T) {
+	dir := t.TempDir()
+	opts := DefaultOptions()
+	opts.CacheChunks = 4
+	store, err := Open(dir, opts)
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+	if _, err := store.Append(context.Background(), "a", linePoints(100, 120.0)); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	half := BBox{Min: Point{Longitude: 120.0, Latitude: 29}, Max: Point{Longitude: 120.015, Latitude: 31}}
+	queries := []Query{{}, {BBox: &half}}
+	want := make([]int, len(queries))
+	for i, q := range queries {
+		for _, err := range store.Query(context.Background(), q) {
+			if err != nil {
+				t.Fatalf("查询失败: %v", err)
+			}
+			want[i]++
+		}
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < 8; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 20 {
+				q := queries[i%len(queries)]
+				got := 0
+				for _, err := range store.Query(context.Background(), q) {
+					if err != nil {
+						t.Errorf("查询失败: %v", err)
+						return
+					}
+					got++
+				}
+				if got != want[i%len(queries)] {
+					t.Errorf("查询结果数量期望 %d，实际 %d", want[i%len(queries)], got)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	st := store.Stats()
+	if st.Points != 100 || st.Chunks == 0 || st.Trajectories != 1 || st.CacheHits == 0 {
+		t.Errorf("存储概况不正确: %+v", st)
+	}
+}
+
+func TestLookupReturnsCopy(t *testing.T) {
+	opts := DefaultOptions()
+	opts.CacheChunks = 4
+	store, err := Open(t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+	points := linePoints(20, 120.0)
+	for i := range points {
+		points[i].Attrs = &Attributes{Fields: AttrSpeed, Speed: 3, Extra: map[string]string{"备注": "a"}}
+	}
+	if _, err := store.Append(context.Background(), "a", points); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	found, err := store.Lookup(points[3])
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	want := found[0]
+	found[0].Longitude = 0
+	if again, _ := store.Lookup(points[3]); again[0].Longitude != want.Longitude {
+		t.Errorf("修改 Lookup 的结果不应影响缓存: %+v", again[0])
+	}
+
+	// 附加属性与修改前的点同样是副本
+	found[0].Attrs.Speed = 0
+	found[0].Attrs.Extra["备注"] = "b"
+	again, _ := store.LookupChunk(points[3])
+	if attrs := again.Points[0].Attrs; attrs == nil || attrs.Speed != 3 || attrs.Extra["备注"] != "a" {
+		t.Errorf("修改附加属性不应影响缓存: %+v", attrs)
+	}
+	if again.Points[0].Attrs == found[0].Attrs {
+		t.Errorf("每次查询应返回新的附加属性副本")
+	}
+
+	original := Point{Longitude: 1, Attrs: &Attributes{Extra: map[string]string{"备注": "a"}}}
+	cached := []Point{{Original: &original}}
+	copied := clonePoints(cached)
+	copied[0].Original.Longitude = 2
+	copied[0].Original.Attrs.Extra["备注"] = "b"
+	if original.Longitude != 1 || original.Attrs.Extra["备注"] != "a" {
+		t.Errorf("修改副本中修改前的点不应影响原数据: %+v", original)
+	}
+}
@@ -0,0 +1,28 @@
+package trackstore
+
+// Clean 按 Append 相同的方式校验并逐块清洗整条轨迹，但不写入存储。
+// 返回的点与通过校验的点一一对应，坐标已转换为 WGS84，Flag 不为 FlagOriginal 的点即为检测出的异常点。
+func Clean(points []Point, opts Options) ([]Point, Validation, error) {
+	if err := opts.Check(); err != nil {
+		return nil, Validation{}, err
+	}
+	points, validation, err := prepare(points, opts)
+	if err != nil {
+		return nil, validation, err
+	}
+	cleaned := make([]Point, 0, len(points))
+	for _, task := range Split(points, opts.MaxLon, opts.MaxLat, opts.Overlap) {
+		task.Metric = opts.Distance
+		task.MaxClimbRate = opts.MaxClimbRate
+		task.WeightHDOP = opts.WeightHDOP
+		task.Clean = opts.Clean
+		task.Kalman = opts.Kalman
+		result, _ := SpeedOutliner(task)
+		if len(result) != task.End-task.Start+1 {
+			// 下标无效时 SpeedOutliner 返回空切片，保留原始点以维持对应关系
+			result = task.Points[task.Start : task.End+1]
+		}
+		cleaned = append(cleaned, result...)
+	}
+	return cleaned, validation, nil
+}
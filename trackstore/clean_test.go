@@ -0,0 +1,32 @@
+package trackstore
+
+import "testing"
+
+func TestClean(t *testing.T) {
+	points := linePoints(60, 120.0)
+	points[30].Latitude += 0.01 // 约 1 千米的跳变
+
+	cleaned, _, err := Clean(points, DefaultOptions())
+	if err != nil {
+		t.Fatalf("清洗失败: %v", err)
+	}
+	if len(cleaned) != len(points) {
+		t.Fatalf("清洗后点数应与输入相同，期望 %d，实际 %d", len(points), len(cleaned))
+	}
+	modified := 0
+	for i := range points {
+		if cleaned[i] == points[i] {
+			continue
+		}
+		modified++
+		if cleaned[i].Flag != FlagInterpolated || cleaned[i].Original == nil || *cleaned[i].Original != points[i] {
+			t.Errorf("第 %d 个点的来源标记不正确: %+v", i, cleaned[i])
+		}
+	}
+	if modified == 0 {
+		t.Errorf("跳变附近的点应被修正")
+	}
+	if cleaned[0] != points[0] || cleaned[59] != points[59] {
+		t.Errorf("正常点不应被修改")
+	}
+}
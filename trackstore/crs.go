@@ -0,0 +1,189 @@
+package trackstore
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CRS 坐标参考系。存储中的坐标统一为 WGS84 经纬度，写入前由输入坐标系转换，
+// 导出时再转换到输出坐标系
+type CRS interface {
+	ToWGS84(x, y float64) (lon, lat float64)
+	FromWGS84(lon, lat float64) (x, y float64)
+	Name() string
+	Geographic() bool // 坐标为经纬度（度）时为 true，投影坐标（米）时为 false
+}
+
+// WGS84 EPSG:4326，GPS 原始坐标
+type WGS84 struct{}
+
+func (WGS84) ToWGS84(x, y float64) (lon, lat float64)   { return x, y }
+func (WGS84) FromWGS84(lon, lat float64) (x, y float64) { return lon, lat }
+func (WGS84) Name() string                              { return "WGS84" }
+func (WGS84) Geographic() bool                          { return true }
+
+// GCJ02 国测局坐标系（“火星坐标”），高德、腾讯等国内地图使用。
+// 只对中国境内的坐标加偏移，境外坐标与 WGS84 相同
+type GCJ02 struct{}
+
+// GCJ-02 偏移算法使用的 Krasovsky 1940 椭球参数
+const (
+	gcjA  = 6378245.0
+	gcjEE = 0.00669342162296594323
+)
+
+// 粗略判断是否在中国境内，境外不加偏移
+func outOfChina(lon, lat float64) bool {
+	return lon < 72.004 || lon > 137.8347 || lat < 0.8293 || lat > 55.8271
+}
+
+func gcjTransformLat(x, y float64) float64 {
+	ret := -100.0 + 2.0*x + 3.0*y + 0.2*y*y + 0.1*x*y + 0.2*math.Sqrt(math.Abs(x))
+	ret += (20.0*math.Sin(6.0*x*math.Pi) + 20.0*math.Sin(2.0*x*math.Pi)) * 2.0 / 3.0
+	ret += (20.0*math.Sin(y*math.Pi) + 40.0*math.Sin(y/3.0*math.Pi)) * 2.0 / 3.0
+	ret += (160.0*math.Sin(y/12.0*math.Pi) + 320*math.Sin(y*math.Pi/30.0)) * 2.0 / 3.0
+	return ret
+}
+
+func gcjTransformLon(x, y float64) float64 {
+	ret := 300.0 + x + 2.0*y + 0.1*x*x + 0.1*x*y + 0.1*math.Sqrt(math.Abs(x))
+	ret += (20.0*math.Sin(6.0*x*math.Pi) + 20.0*math.Sin(2.0*x*math.Pi)) * 2.0 / 3.0
+	ret += (20.0*math.Sin(x*math.Pi) + 40.0*math.Sin(x/3.0*math.Pi)) * 2.0 / 3.0
+	ret += (150.0*math.Sin(x/12.0*math.Pi) + 300.0*math.Sin(x/30.0*math.Pi)) * 2.0 / 3.0
+	return ret
+}
+
+// WGS84 坐标在 GCJ-02 中的偏移量（度）
+func gcjOffset(lon, lat float64) (dLon, dLat float64) {
+	dLat = gcjTransformLat(lon-105.0, lat-35.0)
+	dLon = gcjTransformLon(lon-105.0, lat-35.0)
+	radLat := lat / 180.0 * math.Pi
+	magic := 1 - gcjEE*math.Sin(radLat)*math.Sin(radLat)
+	sqrtMagic := math.Sqrt(magic)
+	dLat = (dLat * 180.0) / ((gcjA * (1 - gcjEE)) / (magic * sqrtMagic) * math.Pi)
+	dLon = (dLon * 180.0) / (gcjA / sqrtMagic * math.Cos(radLat) * math.Pi)
+	return
+}
+
+func (GCJ02) FromWGS84(lon, lat float64) (x, y float64) {
+	if outOfChina(lon, lat) {
+		return lon, lat
+	}
+	dLon, dLat := gcjOffset(lon, lat)
+	return lon + dLon, lat + dLat
+}
+
+// ToWGS84 偏移量随位置缓慢变化，从 GCJ-02 坐标出发迭代求解，误差小于 1e-9 度
+func (g GCJ02) ToWGS84(x, y float64) (lon, lat float64) {
+	if outOfChina(x, y) {
+		return x, y
+	}
+	lon, lat = x, y
+	for i := 0; i < 10; i++ {
+		gx, gy := g.FromWGS84(lon, lat)
+		dx, dy := x-gx, y-gy
+		lon, lat = lon+dx, lat+dy
+		if math.Abs(dx) < 1e-10 && math.Abs(dy) < 1e-10 {
+			break
+		}
+	}
+	return
+}
+
+func (GCJ02) Name() string     { return "GCJ-02" }
+func (GCJ02) Geographic() bool { return true }
+
+// BD09 百度坐标系，在 GCJ-02 的基础上再做一次偏移
+type BD09 struct{}
+
+const bdXPi = math.Pi * 3000.0 / 180.0
+
+func (BD09) FromWGS84(lon, lat float64) (x, y float64) {
+	gx, gy := GCJ02{}.FromWGS84(lon, lat)
+	z := math.Sqrt(gx*gx+gy*gy) + 0.00002*math.Sin(gy*bdXPi)
+	theta := math.Atan2(gy, gx) + 0.000003*math.Cos(gx*bdXPi)
+	return z*math.Cos(theta) + 0.0065, z*math.Sin(theta) + 0.006
+}
+
+// ToWGS84 常用的 BD-09 反算公式只精确到约 1e-6 度，以其结果为初值再迭代修正
+func (b BD09) ToWGS84(x, y float64) (lon, lat float64) {
+	bx, by := x-0.0065, y-0.006
+	z := math.Sqrt(bx*bx+by*by) - 0.00002*math.Sin(by*bdXPi)
+	theta := math.Atan2(by, bx) - 0.000003*math.Cos(bx*bdXPi)
+	lon, lat = GCJ02{}.ToWGS84(z*math.Cos(theta), z*math.Sin(theta))
+	for i := 0; i < 10; i++ {
+		fx, fy := b.FromWGS84(lon, lat)
+		dx, dy := x-fx, y-fy
+		lon, lat = lon+dx, lat+dy
+		if math.Abs(dx) < 1e-10 && math.Abs(dy) < 1e-10 {
+			break
+		}
+	}
+	return
+}
+
+func (BD09) Name() string     { return "BD-09" }
+func (BD09) Geographic() bool { return true }
+
+// ProjectedCRS 以 EPSG 代码标识的 WGS84 投影坐标系，坐标单位为米
+type ProjectedCRS struct {
+	Code       int
+	Projection Projection
+}
+
+func (p ProjectedCRS) ToWGS84(x, y float64) (lon, lat float64) {
+	return p.Projection.Inverse(x, y)
+}
+
+func (p ProjectedCRS) FromWGS84(lon, lat float64) (x, y float64) {
+	return p.Projection.Forward(lon, lat)
+}
+
+func (p ProjectedCRS) Name() string     { return fmt.Sprintf("EPSG:%d", p.Code) }
+func (p ProjectedCRS) Geographic() bool { return false }
+
+// ParseCRS 按名称解析坐标系，不区分大小写：wgs84（或 epsg:4326）、gcj02、bd09、
+// epsg:3857，以及 WGS84 UTM 分带 epsg:32601 至 epsg:32660（北半球）、epsg:32701 至 epsg:32760（南半球）
+func ParseCRS(name string) (CRS, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	key = strings.ReplaceAll(key, "-", "")
+	switch key {
+	case "wgs84", "epsg:4326", "4326":
+		return WGS84{}, nil
+	case "gcj02":
+		return GCJ02{}, nil
+	case "bd09":
+		return BD09{}, nil
+	}
+
+	code, err := strconv.Atoi(strings.TrimPrefix(key, "epsg:"))
+	switch {
+	case err != nil:
+	case code == 3857:
+		return ProjectedCRS{Code: code, Projection: WebMercator{}}, nil
+	case code > 32600 && code <= 32660:
+		return ProjectedCRS{Code: code, Projection: UTM{Zone: code - 32600, North: true}}, nil
+	case code > 32700 && code <= 32760:
+		return ProjectedCRS{Code: code, Projection: UTM{Zone: code - 32700}}, nil
+	}
+	return nil, fmt.Errorf("未知的坐标系: %s，可选 wgs84、gcj02、bd09、epsg:3857 或 UTM 分带 epsg:326xx/327xx", name)
+}
+
+// ToWGS84Points 返回转换为 WGS84 经纬度的点，不修改 points；
+// crs 为 nil 或 WGS84 时直接返回 points
+func ToWGS84Points(points []Point, crs CRS) []Point {
+	if crs == nil {
+		return points
+	}
+	if _, ok := crs.(WGS84); ok {
+		return points
+	}
+	converted := make([]Point, len(points))
+	for i, p := range points {
+		p.Longitude, p.Latitude = crs.ToWGS84(p.Longitude, p.Latitude)
+		converted[i] = p
+	}
+	return converted
+}
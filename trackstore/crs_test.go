@@ -0,0 +1,87 @@
+package trackstore
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestCRSRoundTrip(t *testing.T) {
+	p := Point{Longitude: 116.3912757, Latitude: 39.906217}
+	for _, name := range []string{"wgs84", "GCJ-02", "bd09", "EPSG:3857", "epsg:32650", "epsg:32750"} {
+		crs, err := ParseCRS(name)
+		if err != nil {
+			t.Fatalf("解析 %s 失败: %v", name, err)
+		}
+		x, y := crs.FromWGS84(p.Longitude, p.Latitude)
+		lon, lat := crs.ToWGS84(x, y)
+		if math.Abs(lon-p.Longitude) > 1e-8 || math.Abs(lat-p.Latitude) > 1e-8 {
+			t.Errorf("%s: 往返后为 (%.10f, %.10f)", crs.Name(), lon, lat)
+		}
+	}
+
+	if crs, _ := ParseCRS("epsg:32650"); crs.Geographic() || crs.Name() != "EPSG:32650" {
+		t.Errorf("UTM 分带应为投影坐标系: %s", crs.Name())
+	}
+	if _, err := ParseCRS("epsg:32661"); err == nil {
+		t.Errorf("不存在的 EPSG 代码应返回错误")
+	}
+}
+
+func TestGCJ02Offset(t *testing.T) {
+	// 北京的偏移在几百米量级，BD-09 在 GCJ-02 的基础上再偏移约 1 公里
+	wgs := Point{Longitude: 116.3912757, Latitude: 39.906217}
+	var gcj, bd Point
+	gcj.Longitude, gcj.Latitude = GCJ02{}.FromWGS84(wgs.Longitude, wgs.Latitude)
+	bd.Longitude, bd.Latitude = BD09{}.FromWGS84(wgs.Longitude, wgs.Latitude)
+	if d := Haversine(wgs, gcj); d < 100 || d > 1000 {
+		t.Errorf("GCJ-02 偏移量不正确: %.1f 米", d)
+	}
+	if d := Haversine(gcj, bd); d < 500 || d > 1500 {
+		t.Errorf("BD-09 偏移量不正确: %.1f 米", d)
+	}
+
+	// 境外坐标不加偏移
+	if x, y := (GCJ02{}).FromWGS84(2.35, 48.85); x != 2.35 || y != 48.85 {
+		t.Errorf("境外坐标不应偏移: (%f, %f)", x, y)
+	}
+}
+
+func TestAppendInputCRS(t *testing.T) {
+	opts := DefaultOptions()
+	opts.InputCRS = GCJ02{}
+	store, err := Open(t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+
+	// 写入 GCJ-02 坐标，存储中应为 WGS84，且不修改调用方的切片
+	wgs := linePoints(20, 116.3)
+	input := make([]Point, len(wgs))
+	for i, p := range wgs {
+		input[i] = p
+		input[i].Longitude, input[i].Latitude = GCJ02{}.FromWGS84(p.Longitude, p.Latitude)
+	}
+	before := input[0]
+	if _, err := store.Append(context.Background(), "a", input); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if input[0] != before {
+		t.Errorf("Append 不应修改输入的点")
+	}
+
+	i := 0
+	for p, err := range store.Query(context.Background(), Query{}) {
+		if err != nil {
+			t.Fatalf("查询失败: %v", err)
+		}
+		if math.Abs(p.Longitude-wgs[i].Longitude) > 1e-8 || math.Abs(p.Latitude-wgs[i].Latitude) > 1e-8 {
+			t.Errorf("第 %d 个点应为 (%f, %f)，实际 (%f, %f)", i, wgs[i].Longitude, wgs[i].Latitude, p.Longitude, p.Latitude)
+		}
+		i++
+	}
+	if i != len(wgs) {
+		t.Errorf("期望 %d 个点，实际 %d 个", len(wgs), i)
+	}
+}
@@ -0,0 +1,130 @@
+package trackstore
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Metric 计算两点间的距离（米）。清洗、圆形查询与最近点查询使用 Options.Distance 指定的度量
+type Metric func(a, b Point) float64
+
+// 球面距离使用的平均地球半径（米）
+const earthRadius = 6371000
+
+// Haversine 球面大圆距离，相对椭球面距离的误差最大约 0.5%
+func Haversine(a, b Point) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	deltaLat := (b.Latitude - a.Latitude) * math.Pi / 180
+	deltaLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLon/2)*math.Sin(deltaLon/2)
+	return 2 * earthRadius * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
+
+// EquirectangularDistance 以两点平均纬度处的等距圆柱投影近似，只需一次余弦与开方，
+// 适合相邻轨迹点之间这类几公里以内的距离。经度差折算到 [-180, 180]，跨越 180° 经线时取短的一侧
+func EquirectangularDistance(a, b Point) float64 {
+	dLon := math.Remainder(b.Longitude-a.Longitude, 360)
+	x := dLon * math.Pi / 180 * math.Cos((a.Latitude+b.Latitude)/2*math.Pi/180)
+	y := (b.Latitude - a.Latitude) * math.Pi / 180
+	return earthRadius * math.Hypot(x, y)
+}
+
+// Distance3D 在水平距离 metric(a, b) 的基础上计入高差；任一点没有海拔时只计水平距离
+func Distance3D(metric Metric, a, b Point) float64 {
+	d := metric.orDefault()(a, b)
+	if !a.HasAltitude || !b.HasAltitude {
+		return d
+	}
+	return math.Hypot(d, b.Altitude-a.Altitude)
+}
+
+// vincentyMaxIter Vincenty 反算的最大迭代次数，近对跖点时可能不收敛
+const vincentyMaxIter = 200
+
+// Vincenty WGS84 椭球面上的测地线距离（Vincenty 反算），精度在毫米级；
+// 两点接近对跖点导致迭代不收敛时退回 Haversine
+func Vincenty(a, b Point) float64 {
+	if d, ok := vincentyInverse(a, b); ok {
+		return d
+	}
+	return Haversine(a, b)
+}
+
+func vincentyInverse(p1, p2 Point) (float64, bool) {
+	const f = wgs84F
+	const bAxis = wgs84A * (1 - f)
+	if p1.Longitude == p2.Longitude && p1.Latitude == p2.Latitude {
+		return 0, true
+	}
+
+	l := (p2.Longitude - p1.Longitude) * math.Pi / 180
+	u1 := math.Atan((1 - f) * math.Tan(p1.Latitude*math.Pi/180))
+	u2 := math.Atan((1 - f) * math.Tan(p2.Latitude*math.Pi/180))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+
+	lambda := l
+	var sinSigma, cosSigma, sigma, cos2Alpha, cos2SigmaM float64
+	converged := false
+	for i := 0; i < vincentyMaxIter; i++ {
+		sinLambda, cosLambda := math.Sincos(lambda)
+		sinSigma = math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			return 0, true // 重合点
+		}
+		cosSigma = sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma = math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha = 1 - sinAlpha*sinAlpha
+		cos2SigmaM = 0
+		if cos2Alpha != 0 {
+			// 两点都在赤道上时 cos2Alpha 为 0
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+		c := f / 16 * cos2Alpha * (4 + f*(4-3*cos2Alpha))
+		prev := lambda
+		lambda = l + (1-c)*f*sinAlpha*(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-prev) < 1e-12 {
+			converged = true
+			break
+		}
+	}
+	if !converged || math.Abs(lambda) > math.Pi {
+		return 0, false
+	}
+
+	uSq := cos2Alpha * (wgs84A*wgs84A - bAxis*bAxis) / (bAxis * bAxis)
+	aa := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+	bb := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+	deltaSigma := bb * sinSigma * (cos2SigmaM + bb/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		bb/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+	return bAxis * aa * (sigma - deltaSigma), true
+}
+
+// MetricNames 可供 ParseMetric 使用的距离度量名称
+var MetricNames = []string{"haversine", "vincenty", "equirectangular"}
+
+// ParseMetric 按名称返回距离度量，名称不区分大小写
+func ParseMetric(name string) (Metric, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "haversine":
+		return Haversine, nil
+	case "vincenty":
+		return Vincenty, nil
+	case "equirectangular":
+		return EquirectangularDistance, nil
+	}
+	return nil, fmt.Errorf("未知的距离度量: %s，可选 %s", name, strings.Join(MetricNames, "、"))
+}
+
+// 为 nil 时使用 Haversine
+func (m Metric) orDefault() Metric {
+	if m == nil {
+		return Haversine
+	}
+	return m
+}
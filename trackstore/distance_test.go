@@ -0,0 +1,107 @@
+package trackstore
+
+import (
+	"math"
+	"testing"
+)
+
+// Vincenty 原论文中的算例：Flinders Peak 到 Buninyong
+var (
+	flindersPeak = Point{Longitude: 144 + 25/60.0 + 29.52440/3600, Latitude: -(37 + 57/60.0 + 3.72030/3600)}
+	buninyong    = Point{Longitude: 143 + 55/60.0 + 35.38390/3600, Latitude: -(37 + 39/60.0 + 10.15610/3600)}
+)
+
+func TestVincenty(t *testing.T) {
+	if d := Vincenty(flindersPeak, buninyong); math.Abs(d-54972.271) > 1e-3 {
+		t.Errorf("Flinders Peak 到 Buninyong 应为 54972.271 米，实际 %.4f", d)
+	}
+	if d := Vincenty(flindersPeak, flindersPeak); d != 0 {
+		t.Errorf("重合点距离应为 0，实际 %f", d)
+	}
+	// 赤道上经度相差 1° 为赤道周长的 1/360
+	if d := Vincenty(Point{}, Point{Longitude: 1}); math.Abs(d-2*math.Pi*wgs84A/360) > 1e-6 {
+		t.Errorf("赤道上 1° 的距离不正确: %f", d)
+	}
+
+	// 近对跖点不收敛，退回 Haversine
+	a, b := Point{}, Point{Longitude: 179.7, Latitude: 0.5}
+	if _, ok := vincentyInverse(a, b); ok {
+		t.Fatalf("近对跖点应不收敛")
+	}
+	if d := Vincenty(a, b); d != Haversine(a, b) {
+		t.Errorf("不收敛时应退回 Haversine，实际 %f", d)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	// 相邻轨迹点间距很小时三种度量的差异应在 0.5% 以内
+	for _, tc := range []struct {
+		name string
+		a, b Point
+	}{
+		{"相邻点", Point{Longitude: 116.3, Latitude: 39.9}, Point{Longitude: 116.3003, Latitude: 39.9001}},
+		{"跨越 180° 经线", Point{Longitude: 179.9998, Latitude: -16.5}, Point{Longitude: -179.9999, Latitude: -16.5001}},
+	} {
+		want := Vincenty(tc.a, tc.b)
+		for _, name := range MetricNames {
+			metric, err := ParseMetric(name)
+			if err != nil {
+				t.Fatalf("解析 %s 失败: %v", name, err)
+			}
+			if d := metric(tc.a, tc.b); math.Abs(d-want)/want > 0.005 {
+				t.Errorf("%s %s: %f，与 Vincenty 的 %f 相差过大", tc.name, name, d, want)
+			}
+		}
+	}
+	if _, err := ParseMetric("manhattan"); err == nil {
+		t.Errorf("未知度量应返回错误")
+	}
+}
+
+func TestDistance3D(t *testing.T) {
+	a := Point{Longitude: 116.3, Latitude: 39.9, Altitude: 50, HasAltitude: true}
+	b := Point{Longitude: 116.3003, Latitude: 39.9001, Altitude: 90, HasAltitude: true}
+	horizontal := Haversine(a, b)
+	if d := Distance3D(nil, a, b); math.Abs(d-math.Hypot(horizontal, 40)) > 1e-9 {
+		t.Errorf("三维距离不正确: %f", d)
+	}
+	b.HasAltitude = false
+	if d := Distance3D(Haversine, a, b); d != horizontal {
+		t.Errorf("缺少海拔时应只计水平距离: %f", d)
+	}
+}
+
+var benchDistance float64
+
+func benchmarkMetric(b *testing.B, metric Metric, p, q Point) {
+	for i := 0; i < b.N; i++ {
+		benchDistance = metric(p, q)
+	}
+}
+
+// 相邻轨迹点，清洗时的典型输入
+var benchA, benchB = Point{Longitude: 116.3, Latitude: 39.9}, Point{Longitude: 116.3003, Latitude: 39.9001}
+
+func BenchmarkHaversine(b *testing.B) { benchmarkMetric(b, Haversine, benchA, benchB) }
+
+func BenchmarkVincenty(b *testing.B) { benchmarkMetric(b, Vincenty, benchA, benchB) }
+
+func BenchmarkVincentyLong(b *testing.B) { benchmarkMetric(b, Vincenty, flindersPeak, buninyong) }
+
+func BenchmarkEquirectangular(b *testing.B) {
+	benchmarkMetric(b, EquirectangularDistance, benchA, benchB)
+}
+
+// 整个数据块的清洗耗时，比较不同度量对 SpeedOutliner 的影响
+func BenchmarkSpeedOutliner(b *testing.B) {
+	points := linePoints(1000, 120.0)
+	for _, name := range MetricNames {
+		metric, _ := ParseMetric(name)
+		task := Data{Points: points, Start: 0, End: len(points) - 1, Metric: metric}
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				SpeedOutliner(task)
+			}
+		})
+	}
+}
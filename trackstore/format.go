@@ -0,0 +1,134 @@
+package trackstore
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 磁盘格式：每个文件以 4 字节魔数开头，随后依次是 gob 编码的 FileHeader 与数据本体。
+// 版本 1 为早期无文件头的裸 gob 文件，需要通过 MIGRATE 升级。
+const (
+	formatMagic   = "TRKS"
+	formatVersion = 8
+	toolVersion   = "TrackHelper 0.8.0"
+)
+
+// 文件类型
+const (
+	kindIndex = "index"
+	kindChunk = "chunk"
+)
+
+// 旧版本文件（无魔数）
+var errLegacyFormat = errors.New("文件缺少格式头，属于版本 1 的旧格式")
+
+type FileHeader struct {
+	Version int
+	Kind    string
+	Tool    string
+	Created time.Time
+	// 数据块文件所属的轨迹与在轨迹中的序号，与索引表中的 ChunkMeta 一致，供 REINDEX 恢复
+	Trajectory string
+	Seq        int
+}
+
+// 读取文件头，返回的 decoder 用于继续解码数据本体；
+// 旧格式文件返回 Version 为 1 的文件头和 errLegacyFormat
+func readHeader(r io.Reader) (FileHeader, *gob.Decoder, error) {
+	magic := make([]byte, len(formatMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || !bytes.Equal(magic, []byte(formatMagic)) {
+		return FileHeader{Version: 1}, nil, errLegacyFormat
+	}
+
+	decoder := gob.NewDecoder(r)
+	var header FileHeader
+	if err := decoder.Decode(&header); err != nil {
+		return header, nil, fmt.Errorf("解码文件头失败: %v", err)
+	}
+	return header, decoder, nil
+}
+
+// 按当前版本写文件：先写入临时文件再重命名，避免留下写了一半的文件
+func encodeFile(path, kind string, v any) error {
+	return encodeWithHeader(path, FileHeader{Kind: kind}, v)
+}
+
+// 写数据块文件，文件头中记下所属轨迹与序号
+func encodeChunk(path string, points []Point, trajectory string, seq int) error {
+	return encodeWithHeader(path, FileHeader{Kind: kindChunk, Trajectory: trajectory, Seq: seq}, points)
+}
+
+// 按当前版本写文件，header 的版本、工具与创建时间由本函数填写
+func encodeWithHeader(path string, header FileHeader, v any) error {
+	// 临时文件名唯一，允许多个 goroutine 同时写同一目标
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建文件失败: %v", err)
+	}
+	tmpPath := file.Name()
+
+	header.Version = formatVersion
+	header.Tool = toolVersion
+	header.Created = time.Now()
+	_, err = file.Write([]byte(formatMagic))
+	if err == nil {
+		encoder := gob.NewEncoder(file)
+		if err = encoder.Encode(header); err == nil {
+			err = encoder.Encode(v)
+		}
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("序列化失败: %v", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("写入文件失败: %v", err)
+	}
+	return nil
+}
+
+// 读取当前版本的文件；版本不符时提示运行 MIGRATE
+func decodeFile(path, kind string, v any) (FileHeader, error) {
+	return decodeVersioned(path, kind, formatVersion, v)
+}
+
+// 读取格式版本不低于 minVersion 的带文件头的文件，供升级步骤读取旧版本文件
+func decodeVersioned(path, kind string, minVersion int, v any) (FileHeader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return FileHeader{}, err
+	}
+	defer file.Close()
+
+	header, decoder, err := readHeader(file)
+	if err != nil {
+		if errors.Is(err, errLegacyFormat) {
+			return header, fmt.Errorf("%w，请先运行 MIGRATE", err)
+		}
+		return header, err
+	}
+	if header.Kind != kind {
+		return header, fmt.Errorf("文件类型为 %q，期望 %q", header.Kind, kind)
+	}
+	if header.Version > formatVersion {
+		return header, fmt.Errorf("文件由 %s 创建（格式版本 %d），当前程序仅支持到版本 %d", header.Tool, header.Version, formatVersion)
+	}
+	if header.Version < minVersion {
+		return header, fmt.Errorf("文件格式版本 %d 已过时，请先运行 MIGRATE", header.Version)
+	}
+
+	if err := decoder.Decode(v); err != nil {
+		return header, fmt.Errorf("解码失败: %v", err)
+	}
+	return header, nil
+}
@@ -0,0 +1,112 @@
+package trackstore
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// GPX 1.0/1.1 中用到的元素，忽略命名空间
+type gpxFile struct {
+	Tracks []struct {
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+	Routes []struct {
+		Points []gpxPoint `xml:"rtept"`
+	} `xml:"rte"`
+}
+
+type gpxPoint struct {
+	Lat    float64  `xml:"lat,attr"`
+	Lon    float64  `xml:"lon,attr"`
+	Ele    *float64 `xml:"ele"`
+	Time   string   `xml:"time"`
+	Speed  *float64 `xml:"speed"`  // GPX 1.0
+	Course *float64 `xml:"course"` // GPX 1.0
+	HDOP   *float64 `xml:"hdop"`
+	Sat    *int     `xml:"sat"`
+}
+
+// GPX 中记录的速度、航向、HDOP 与卫星数，都没有时返回 nil
+func (gp gpxPoint) attrs() *Attributes {
+	attrs := &Attributes{}
+	if gp.Speed != nil {
+		attrs.Speed, attrs.Fields = *gp.Speed, attrs.Fields|AttrSpeed
+	}
+	if gp.Course != nil {
+		attrs.Heading, attrs.Fields = *gp.Course, attrs.Fields|AttrHeading
+	}
+	if gp.HDOP != nil {
+		attrs.HDOP, attrs.Fields = *gp.HDOP, attrs.Fields|AttrHDOP
+	}
+	if gp.Sat != nil {
+		attrs.Satellites, attrs.Fields = *gp.Sat, attrs.Fields|AttrSatellites
+	}
+	if attrs.Fields == 0 {
+		return nil
+	}
+	return attrs
+}
+
+// ReadGPX 读取 GPX 文件中全部航迹段的点（trkpt），没有航迹时读取航线（rtept）；
+// <ele> 为海拔，<time> 为定位时间，<speed>、<course>、<hdop>、<sat> 保存到 Attributes。
+// <time> 无法解析时保留该点、时间为零值，并返回 *ReadError
+func ReadGPX(path string) ([]Point, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseGPX(file)
+}
+
+func parseGPX(r io.Reader) ([]Point, error) {
+	var doc gpxFile
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("解析 GPX 失败: %v", err)
+	}
+
+	var raw []gpxPoint
+	for _, trk := range doc.Tracks {
+		for _, seg := range trk.Segments {
+			raw = append(raw, seg.Points...)
+		}
+	}
+	if len(raw) == 0 {
+		for _, rte := range doc.Routes {
+			raw = append(raw, rte.Points...)
+		}
+	}
+
+	var problems ReadError
+	points := make([]Point, 0, len(raw))
+	for i, gp := range raw {
+		point := Point{Longitude: gp.Lon, Latitude: gp.Lat, Attrs: gp.attrs()}
+		if gp.Ele != nil {
+			point.Altitude, point.HasAltitude = *gp.Ele, true
+		}
+		if s := strings.TrimSpace(gp.Time); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				problems.add(fmt.Errorf("第 %d 个点的时间: %v", i, err), false)
+			}
+			point.Time = t
+		}
+		points = append(points, point)
+	}
+	return points, problems.orNil()
+}
+
+// ReadTrack 按扩展名读取轨迹文件: .gpx 为 GPX，其余按 XLSX 读取
+func ReadTrack(path string) ([]Point, error) {
+	if strings.EqualFold(filepath.Ext(path), ".gpx") {
+		return ReadGPX(path)
+	}
+	return ReadXLSX(path)
+}
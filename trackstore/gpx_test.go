@@ -0,0 +1,76 @@
+package trackstore
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk><name>hike</name>
+    <trkseg>
+      <trkpt lat="39.9" lon="116.3"><ele>52.5</ele><time>2025-03-01T08:00:00Z</time></trkpt>
+      <trkpt lat="39.9001" lon="116.3003"><time>2025-03-01T08:00:05.5Z</time><hdop>2.5</hdop><sat>7</sat></trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="39.9002" lon="116.3006"><ele>-3</ele></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+func TestParseGPX(t *testing.T) {
+	points, err := parseGPX(strings.NewReader(testGPX))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	start := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	want := []Point{
+		{Longitude: 116.3, Latitude: 39.9, Altitude: 52.5, HasAltitude: true, Time: start},
+		{Longitude: 116.3003, Latitude: 39.9001, Time: start.Add(5500 * time.Millisecond)},
+		{Longitude: 116.3006, Latitude: 39.9002, Altitude: -3, HasAltitude: true},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("期望 %d 个点，实际 %d 个", len(want), len(points))
+	}
+	for i := range want {
+		if points[i].Longitude != want[i].Longitude || points[i].Latitude != want[i].Latitude ||
+			points[i].Altitude != want[i].Altitude || points[i].HasAltitude != want[i].HasAltitude || !points[i].Time.Equal(want[i].Time) {
+			t.Errorf("第 %d 个点期望 %+v，实际 %+v", i, want[i], points[i])
+		}
+	}
+
+	if points[0].Attrs != nil {
+		t.Errorf("没有附加属性的点 Attrs 应为 nil")
+	}
+	if a := points[1].Attrs; !a.Has(AttrHDOP) || a.HDOP != 2.5 || !a.Has(AttrSatellites) || a.Satellites != 7 || a.Has(AttrSpeed) {
+		t.Errorf("附加属性不正确: %+v", a)
+	}
+
+	if _, err := parseGPX(strings.NewReader("<gpx>")); err == nil {
+		t.Errorf("格式错误的 GPX 应返回错误")
+	}
+}
+
+func TestParseGPXBadTime(t *testing.T) {
+	doc := `<gpx><trk><trkseg>
+      <trkpt lat="39.9" lon="116.3"><time>2025-03-01T08:00:00Z</time></trkpt>
+      <trkpt lat="39.9001" lon="116.3003"><ele>60</ele><time>yesterday</time></trkpt>
+      <trkpt lat="39.9002" lon="116.3006"><time>2025-03-01T08:00:10Z</time></trkpt>
+    </trkseg></trk></gpx>`
+	points, err := parseGPX(strings.NewReader(doc))
+	var readErr *ReadError
+	if !errors.As(err, &readErr) || readErr.Skipped != 0 || readErr.BadFields != 1 {
+		t.Fatalf("时间无法解析时应返回 ReadError，实际 %v", err)
+	}
+	if len(points) != 3 {
+		t.Fatalf("时间无法解析的点应保留，实际 %d 个点", len(points))
+	}
+	if p := points[1]; !p.Time.IsZero() || p.Longitude != 116.3003 || !p.HasAltitude || p.Altitude != 60 {
+		t.Errorf("时间无法解析的点应保留位置与海拔、时间为零值: %+v", p)
+	}
+	if points[2].Time.IsZero() {
+		t.Errorf("之后的点不受影响")
+	}
+}
@@ -0,0 +1,159 @@
+package trackstore
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// ErrEmptyExtent 没有满足条件的数据块，无法确定格网范围
+var ErrEmptyExtent = errors.New("格网范围为空")
+
+// HeatmapOptions 格网划分与加权方式
+type HeatmapOptions struct {
+	Cols int // 列数
+	Rows int // 行数，为 0 时按范围的宽高比确定，并将南北范围调整到使格网单元为正方形
+	// Dwell 为 true 时每个点按停留时间（到同一轨迹下一个点的时间，秒）加权，
+	// 没有定位时间的点与轨迹的最后一个点权重为 0；否则每个点计 1
+	Dwell bool
+	// MaxDwell 单个点停留时间的上限，避免数据中断或离开查询范围造成的长间隔
+	// 被计为停留；0 表示不限制
+	MaxDwell time.Duration
+}
+
+func DefaultHeatmapOptions() HeatmapOptions {
+	return HeatmapOptions{Cols: 256, MaxDwell: 10 * time.Minute}
+}
+
+// Heatmap EPSG:3857 坐标（米）下的规则格网，第 0 行在最北边
+type Heatmap struct {
+	Cols, Rows int
+	MinX, MinY float64   // 左下角
+	MaxX, MaxY float64   // 右上角
+	Values     []float64 // 按行存储，长度为 Cols*Rows
+}
+
+// Value 返回第 row 行、第 col 列格网单元的值
+func (h *Heatmap) Value(col, row int) float64 {
+	return h.Values[row*h.Cols+col]
+}
+
+// CellSize 格网单元的宽与高（米）
+func (h *Heatmap) CellSize() (dx, dy float64) {
+	return (h.MaxX - h.MinX) / float64(h.Cols), (h.MaxY - h.MinY) / float64(h.Rows)
+}
+
+// CellCenter 格网单元中心的经纬度
+func (h *Heatmap) CellCenter(col, row int) (lon, lat float64) {
+	dx, dy := h.CellSize()
+	return WebMercator{}.Inverse(h.MinX+(float64(col)+0.5)*dx, h.MaxY-(float64(row)+0.5)*dy)
+}
+
+// Max 格网中的最大值
+func (h *Heatmap) Max() float64 {
+	m := 0.0
+	for _, v := range h.Values {
+		m = max(m, v)
+	}
+	return m
+}
+
+// Heatmap 将满足查询条件的点按 EPSG:3857 坐标统计到格网中。
+// 设置了 q.BBox 时以其为格网范围，否则取所有满足条件的数据块外包矩形的并集。
+func (s *Store) Heatmap(ctx context.Context, q Query, opts HeatmapOptions) (*Heatmap, error) {
+	if opts.Cols <= 0 || opts.Rows < 0 {
+		return nil, errors.New("格网行列数必须大于 0")
+	}
+
+	var lo, hi Point
+	if q.BBox != nil {
+		lo, hi = q.BBox.Min, q.BBox.Max
+	} else {
+		filters := q.filters(s.opts.Distance)
+		found := false
+		for _, meta := range s.index.Metas() {
+			if !q.matchChunk(meta, filters) {
+				continue
+			}
+			if !found {
+				lo, hi, found = meta.Min, meta.Max, true
+				continue
+			}
+			lo.Longitude = min(lo.Longitude, meta.Min.Longitude)
+			lo.Latitude = min(lo.Latitude, meta.Min.Latitude)
+			hi.Longitude = max(hi.Longitude, meta.Max.Longitude)
+			hi.Latitude = max(hi.Latitude, meta.Max.Latitude)
+		}
+		if !found {
+			return nil, ErrEmptyExtent
+		}
+	}
+
+	h := &Heatmap{Cols: opts.Cols, Rows: opts.Rows}
+	h.MinX, h.MinY = WebMercator{}.Forward(lo.Longitude, lo.Latitude)
+	h.MaxX, h.MaxY = WebMercator{}.Forward(hi.Longitude, hi.Latitude)
+	if h.MaxX <= h.MinX || h.MaxY <= h.MinY {
+		// 只有一个点或所有点在一条直线上时范围退化，向外扩展一点
+		const pad = 0.01
+		h.MinX, h.MaxX = h.MinX-pad, h.MaxX+pad
+		h.MinY, h.MaxY = h.MinY-pad, h.MaxY+pad
+	}
+	if h.Rows == 0 {
+		size := (h.MaxX - h.MinX) / float64(h.Cols)
+		h.Rows = max(1, int(math.Ceil((h.MaxY-h.MinY)/size)))
+		pad := (float64(h.Rows)*size - (h.MaxY - h.MinY)) / 2
+		h.MinY, h.MaxY = h.MinY-pad, h.MaxY+pad
+	}
+	h.Values = make([]float64, h.Cols*h.Rows)
+
+	add := func(p Point, w float64) {
+		x, y := WebMercator{}.Forward(p.Longitude, p.Latitude)
+		col := int((x - h.MinX) / (h.MaxX - h.MinX) * float64(h.Cols))
+		row := int((h.MaxY - y) / (h.MaxY - h.MinY) * float64(h.Rows))
+		// 落在右边界、下边界上的点计入最后一列、最后一行
+		if col == h.Cols {
+			col--
+		}
+		if row == h.Rows {
+			row--
+		}
+		if col < 0 || col >= h.Cols || row < 0 || row >= h.Rows {
+			return
+		}
+		h.Values[row*h.Cols+col] += w
+	}
+
+	// 停留时间要等到同一轨迹的下一个点才能确定，上一个点暂存在 prev 中
+	var prev *Point
+	var prevTrajectory string
+	for chunk, err := range s.QueryChunks(ctx, q) {
+		if err != nil {
+			return nil, err
+		}
+		for i := range chunk.Points {
+			p := &chunk.Points[i]
+			if !opts.Dwell {
+				add(*p, 1)
+				continue
+			}
+			if prev != nil && prevTrajectory == chunk.Meta.Trajectory {
+				add(*prev, dwellSeconds(*prev, *p, opts.MaxDwell))
+			}
+			prev, prevTrajectory = p, chunk.Meta.Trajectory
+		}
+	}
+	return h, nil
+}
+
+// 在 a 点停留的秒数，即 a 到 b 的时间间隔
+func dwellSeconds(a, b Point, limit time.Duration) float64 {
+	if a.Time.IsZero() || b.Time.IsZero() || !b.Time.After(a.Time) {
+		return 0
+	}
+	d := b.Time.Sub(a.Time)
+	if limit > 0 && d > limit {
+		d = limit
+	}
+	return d.Seconds()
+}
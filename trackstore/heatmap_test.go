@@ -0,0 +1,83 @@
+package trackstore
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHeatmap(t *testing.T) {
+	store, err := Open(t.TempDir(), DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+
+	// 前 10 个点每秒一个，之后在最后一个点停留 100 秒，再继续每秒一个
+	start := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	points := linePoints(20, 120.0)
+	for i := range points {
+		points[i].Time = start.Add(time.Duration(i) * time.Second)
+		if i >= 10 {
+			points[i].Time = points[i].Time.Add(100 * time.Second)
+		}
+	}
+	if _, err := store.Append(context.Background(), "a", points); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	// 点沿经度等间距分布，每列 2 个点
+	opts := HeatmapOptions{Cols: 10, Rows: 1}
+	h, err := store.Heatmap(context.Background(), Query{}, opts)
+	if err != nil {
+		t.Fatalf("生成格网失败: %v", err)
+	}
+	total := 0.0
+	for _, v := range h.Values {
+		total += v
+	}
+	if total != 20 {
+		t.Errorf("总点数应为 20，实际 %v", total)
+	}
+	if h.Value(0, 0) != 2 || h.Value(9, 0) != 2 {
+		t.Errorf("两端格网单元应各有 2 个点: %v", h.Values)
+	}
+	if lon, _ := h.CellCenter(0, 0); lon < points[0].Longitude || lon > points[2].Longitude {
+		t.Errorf("格网单元中心经度不正确: %f", lon)
+	}
+
+	// 按停留时间加权时第 10 个点所在的单元最大，超过上限的部分不计
+	opts.Dwell, opts.MaxDwell = true, 50*time.Second
+	h, err = store.Heatmap(context.Background(), Query{}, opts)
+	if err != nil {
+		t.Fatalf("生成格网失败: %v", err)
+	}
+	if v := h.Value(4, 0); v != 51 {
+		t.Errorf("停留点所在单元应为 51 秒，实际 %v", v)
+	}
+	if v := h.Value(9, 0); v != 1 {
+		t.Errorf("最后一个点不计停留时间，末尾单元应为 1 秒，实际 %v", v)
+	}
+	if h.Max() != 51 {
+		t.Errorf("最大值应为 51，实际 %v", h.Max())
+	}
+
+	// 行数为 0 时按宽高比确定，单元为正方形
+	box := BBox{Min: Point{Longitude: 120, Latitude: 30}, Max: Point{Longitude: 120.01, Latitude: 30.01}}
+	h, err = store.Heatmap(context.Background(), Query{BBox: &box}, HeatmapOptions{Cols: 100})
+	if err != nil {
+		t.Fatalf("生成格网失败: %v", err)
+	}
+	want := 100 * math.Log(math.Tan(math.Pi/4+30.01*math.Pi/360)/math.Tan(math.Pi/4+30*math.Pi/360)) / (0.01 * math.Pi / 180)
+	if math.Abs(float64(h.Rows)-want) > 1 {
+		t.Errorf("行数应约为 %.1f，实际 %d", want, h.Rows)
+	}
+	if dx, dy := h.CellSize(); math.Abs(dx-dy) > 1e-6 {
+		t.Errorf("格网单元应为正方形: %g × %g", dx, dy)
+	}
+
+	if _, err := store.Heatmap(context.Background(), Query{Trajectory: "b"}, opts); err != ErrEmptyExtent {
+		t.Errorf("没有数据块时应返回 ErrEmptyExtent，实际 %v", err)
+	}
+}
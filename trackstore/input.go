@@ -0,0 +1,285 @@
+package trackstore
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"fmt"
+	"github.com/tealeg/xlsx"
+)
+
+// 时间列支持的文本格式
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
+// 解析时间单元格：Excel 日期、文本格式的时间或 Unix 秒
+func parseTimeCell(cell *xlsx.Cell, date1904 bool) (time.Time, error) {
+	if cell.IsTime() {
+		return cell.GetTime(date1904)
+	}
+	s := strings.TrimSpace(cell.String())
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Unix(0, int64(sec*1e9)), nil
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间: %s", s)
+}
+
+// 各列在行中的下标，-1 表示没有该列
+type columnLayout struct {
+	lon, lat, time, alt       int
+	speed, heading, hdop, sat int
+	accuracy                  int
+	extra                     map[int]string // 其他列的下标到列名
+}
+
+// 没有表头时按位置读取：经度、纬度、定位时间、海拔
+var positionalLayout = columnLayout{lon: 0, lat: 1, time: 2, alt: 3, speed: -1, heading: -1, hdop: -1, sat: -1, accuracy: -1}
+
+// 第一行的第一个单元格不是数值且包含经度、纬度列时作为表头，未识别的列作为其他列保存
+func headerLayout(row *xlsx.Row) (columnLayout, bool) {
+	if row == nil || len(row.Cells) < 2 {
+		return columnLayout{}, false
+	}
+	if _, err := strconv.ParseFloat(strings.TrimSpace(row.Cells[0].String()), 64); err == nil {
+		return columnLayout{}, false
+	}
+	layout := columnLayout{lon: -1, lat: -1, time: -1, alt: -1, speed: -1, heading: -1, hdop: -1, sat: -1, accuracy: -1, extra: map[int]string{}}
+	known := map[string]*int{
+		"lon": &layout.lon, "lat": &layout.lat, "time": &layout.time, "alt": &layout.alt,
+		"speed": &layout.speed, "heading": &layout.heading, "hdop": &layout.hdop, "sat": &layout.sat,
+		"accuracy": &layout.accuracy,
+	}
+	for i, cell := range row.Cells {
+		name := strings.TrimSpace(cell.String())
+		if name == "" {
+			continue
+		}
+		if idx, ok := known[knownColumn(name)]; ok && *idx < 0 {
+			*idx = i
+			continue
+		}
+		layout.extra[i] = name
+	}
+	return layout, layout.lon >= 0 && layout.lat >= 0
+}
+
+// 第 idx 个单元格的文本，没有该列时返回空字符串
+func cellText(row *xlsx.Row, idx int) string {
+	if idx < 0 || idx >= len(row.Cells) {
+		return ""
+	}
+	return strings.TrimSpace(row.Cells[idx].String())
+}
+
+// 按列读取一行，date1904 用于解析 Excel 日期。经纬度无法解析时返回 err；
+// 可选列无法解析时该字段留空，问题记在 bad 中，点仍然返回
+func (l columnLayout) parseRow(row *xlsx.Row, date1904 bool) (point Point, bad []error, err error) {
+	lon, err1 := strconv.ParseFloat(cellText(row, l.lon), 64)
+	lat, err2 := strconv.ParseFloat(cellText(row, l.lat), 64)
+	if err1 != nil || err2 != nil {
+		return Point{}, nil, fmt.Errorf("%v, %v", err1, err2)
+	}
+	point = Point{Longitude: lon, Latitude: lat}
+	if cellText(row, l.time) != "" {
+		if t, err := parseTimeCell(row.Cells[l.time], date1904); err != nil {
+			bad = append(bad, err)
+		} else {
+			point.Time = t
+		}
+	}
+	if s := cellText(row, l.alt); s != "" {
+		if alt, err := strconv.ParseFloat(s, 64); err != nil {
+			bad = append(bad, fmt.Errorf("海拔 %v", err))
+		} else {
+			point.Altitude, point.HasAltitude = alt, true
+		}
+	}
+
+	attrs := &Attributes{}
+	for _, f := range []struct {
+		name  string
+		idx   int
+		field AttrField
+		value *float64
+	}{{"速度", l.speed, AttrSpeed, &attrs.Speed}, {"航向", l.heading, AttrHeading, &attrs.Heading}, {"HDOP", l.hdop, AttrHDOP, &attrs.HDOP}, {"水平误差", l.accuracy, AttrAccuracy, &attrs.Accuracy}} {
+		s := cellText(row, f.idx)
+		if s == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			bad = append(bad, fmt.Errorf("%s %v", f.name, err))
+			continue
+		}
+		*f.value = v
+		attrs.Fields |= f.field
+	}
+	if s := cellText(row, l.sat); s != "" {
+		if n, err := strconv.Atoi(s); err != nil {
+			bad = append(bad, fmt.Errorf("卫星数 %v", err))
+		} else {
+			attrs.Satellites = n
+			attrs.Fields |= AttrSatellites
+		}
+	}
+	for idx, name := range l.extra {
+		if s := cellText(row, idx); s != "" {
+			if attrs.Extra == nil {
+				attrs.Extra = make(map[string]string)
+			}
+			attrs.Extra[name] = s
+		}
+	}
+	if attrs.Fields != 0 || attrs.Extra != nil {
+		point.Attrs = attrs
+	}
+	return point, bad, nil
+}
+
+// ReadError 读取轨迹文件时跳过的记录与留空的字段。读取函数返回 *ReadError 时
+// 同时返回其余可用的点，调用方可以记录警告后继续使用
+type ReadError struct {
+	Skipped   int   // 无法解析经纬度而跳过的记录
+	BadFields int   // 无法解析而留空的可选字段，所在的点保留
+	First     error // 第一个问题，带有所在的行号或点的序号
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("跳过 %d 条记录，%d 个字段无法解析已留空，第一个问题: %v", e.Skipped, e.BadFields, e.First)
+}
+
+func (e *ReadError) Unwrap() error {
+	return e.First
+}
+
+// 记录一个问题，skipped 为 true 时整条记录被跳过，否则只是一个字段留空
+func (e *ReadError) add(err error, skipped bool) {
+	if skipped {
+		e.Skipped++
+	} else {
+		e.BadFields++
+	}
+	if e.First == nil {
+		e.First = err
+	}
+}
+
+// 没有问题时返回 nil
+func (e *ReadError) orNil() error {
+	if e.Skipped == 0 && e.BadFields == 0 {
+		return nil
+	}
+	return e
+}
+
+// ReadXLSX 读取 XLSX 文件的第一个工作表。第一行为表头时按列名读取：
+// 经度（lon）、纬度（lat）、定位时间（time）、海拔（alt）、速度（speed，米/秒）、航向（heading）、
+// HDOP（hdop）、水平误差（accuracy，米）、卫星数（sat），其余列按列名保存到 Attributes.Extra；
+// 没有表头时依次为经度、纬度、定位时间（可选）、海拔（米，可选）。
+// 经纬度无法解析的行被跳过，其他列无法解析时该字段留空，两者的个数通过 *ReadError 返回，其余的点照常返回
+func ReadXLSX(path string) ([]Point, error) {
+	file, err := xlsx.OpenFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := file.Sheets[0]
+	if sheet == nil {
+		return nil, fmt.Errorf("该文件%s中没有工作表", path)
+	}
+	points := make([]Point, 0, len(sheet.Rows))
+
+	var problems ReadError
+	layout, first := positionalLayout, 0
+	if header, ok := headerLayout(sheet.Row(0)); ok {
+		layout, first = header, 1
+	}
+	for i := first; i < len(sheet.Rows); i++ {
+		row := sheet.Row(i)
+		if row == nil || len(row.Cells) < 2 {
+			continue // 空行
+		}
+		point, bad, err := layout.parseRow(row, file.Date1904)
+		if err != nil {
+			problems.add(fmt.Errorf("第 %d 行: %v", i+1, err), true)
+			continue
+		}
+		for _, err := range bad {
+			problems.add(fmt.Errorf("第 %d 行: %v", i+1, err), false)
+		}
+		points = append(points, point)
+	}
+	return points, problems.orNil()
+}
+
+// Split 按经纬度跨度阈值将轨迹划分为数据块，相邻数据块前后各重叠 extra 个点，extra 为负数时按 0 处理
+func Split(points []Point, maxLon float64, maxLat float64, extra int) []Data {
+	if len(points) == 0 {
+		return nil
+	}
+	// 负的重叠点数会使 Start 为负，所有数据块都被跳过
+	extra = max(extra, 0)
+	
+	var tasks []Data  // 分派给每个线程的任务数据
+	start := 0
+	taskCode := 0
+	
+	for i := 0; i < len(points); i++ {
+		if i == 0  {
+		continue
+	}
+	cumLon := math.Abs(points[i].Longitude - points[start].Longitude)
+	cumLat := math.Abs(points[i].Latitude - points[start].Latitude)
+
+		if cumLon > maxLon || cumLat > maxLat { // 若超出范围，生成任务数据
+			begin := max(0, start - extra)
+			last := min(len(points)-1, i+extra)
+
+			var startidx int
+			if start < extra {
+				startidx = start
+			} else {
+				startidx = extra
+			}
+
+			endidx := startidx + (i - start)
+			
+			task := Data{
+				Points: points[begin : last+1],
+				Start: startidx,
+				End: endidx,
+				TaskCode: taskCode,
+			}
+			tasks = append(tasks, task)
+
+			start = i+1
+			taskCode++
+			// 复原累计值，将起始索引指向当前的位置
+
+		}
+	}
+	if start < len(points) {  // 处理剩下的最后一节
+		begin := max(0, start - extra)
+		last := len(points) - 1
+		// Start/End 为相对 Points 的下标，与前面的数据块一致
+		task := Data{
+			Points: points[begin : last+1],
+			Start: start - begin,
+			End: last - begin,
+			TaskCode: taskCode,
+		}
+		tasks = append(tasks, task)
+
+	}
+	return tasks
+}
@@ -0,0 +1,88 @@
+package trackstore
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// 读取 testdata 中的 XLSX 测试数据，文件不存在时跳过测试
+func readFixture(t *testing.T, name string) ([]Point, error) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		t.Skipf("缺少测试数据 %s", path)
+	}
+	return ReadXLSX(path)
+}
+
+func TestRead(t *testing.T) {
+	points, err := readFixture(t, "testA.xlsx")
+	if err != nil {
+        t.Fatalf("读取文件失败: %v", err)
+    }
+    expectedPoints := []Point{
+        {Longitude: 85.012497, Latitude: 27.729147},
+        {Longitude: 85.013000, Latitude: 27.730000},
+        {Longitude: 85.014000, Latitude: 27.731000},
+        {Longitude: 85.015000, Latitude: 27.732000},
+        {Longitude: 85.016000, Latitude: 27.733000},
+    }
+   if len(points) != len(expectedPoints) {
+        t.Errorf("读取的点数不正确，期望 %d，实际 %d", len(expectedPoints), len(points))
+    }
+
+    for i, p := range points {
+        if p.Longitude != expectedPoints[i].Longitude || p.Latitude != expectedPoints[i].Latitude {
+            t.Errorf("第 %d 个点不匹配，期望 (%f, %f)，实际 (%f, %f)",
+                i, expectedPoints[i].Longitude, expectedPoints[i].Latitude, p.Longitude, p.Latitude)
+        }
+    }
+}
+
+func TestSplit(t *testing.T) {
+	// 经度间隔 0.0003，跨度超过 0.001 时在第 5 个点处划分
+	points := linePoints(12, 120.0)
+	tasks := Split(points, 0.001, 0.001, 1)
+
+	// 每个数据块在 points 中的范围：含重叠的 [begin, last]，数据块本身为 [start, end]
+	expected := []struct{ begin, start, end, last int }{
+		{0, 0, 4, 5},
+		{4, 5, 9, 10},
+		{9, 10, 11, 11},
+	}
+	if len(tasks) != len(expected) {
+		t.Fatalf("任务数量不正确，期望 %d，实际 %d", len(expected), len(tasks))
+	}
+	for i, task := range tasks {
+		want := expected[i]
+		if task.TaskCode != i {
+			t.Errorf("任务 %d 的任务号为 %d", i, task.TaskCode)
+		}
+		// Start/End 为相对 Points 的下标
+		if task.Start != want.start-want.begin || task.End != want.end-want.begin {
+			t.Errorf("任务 %d 的索引范围不匹配，期望 (%d, %d)，实际 (%d, %d)",
+				i, want.start-want.begin, want.end-want.begin, task.Start, task.End)
+		}
+		if !reflect.DeepEqual(task.Points, points[want.begin:want.last+1]) {
+			t.Errorf("任务 %d 的点列表应为 points[%d:%d]，实际 %v", i, want.begin, want.last+1, task.Points)
+		}
+	}
+
+	// 数据块本身首尾相接，覆盖全部点且不重复
+	var covered []Point
+	for _, task := range tasks {
+		covered = append(covered, task.Points[task.Start:task.End+1]...)
+	}
+	if !reflect.DeepEqual(covered, points) {
+		t.Errorf("数据块应依次覆盖全部点: %v", covered)
+	}
+
+	if tasks := Split(points, 0.001, 0.001, 0); tasks[1].Start != 0 || len(tasks[1].Points) != 5 {
+		t.Errorf("不重叠时数据块只包含自身的点: %+v", tasks[1])
+	}
+	if tasks := Split(points, 0.001, 0.001, -1); !reflect.DeepEqual(tasks, Split(points, 0.001, 0.001, 0)) {
+		t.Errorf("重叠点数为负时应按 0 处理: %+v", tasks)
+	}
+}
@@ -0,0 +1,251 @@
+package trackstore
+
+import (
+	"fmt"
+	"log"
+)
+
+// CleanMode 清洗时修正异常点的方式
+type CleanMode uint8
+
+const (
+	CleanInterpolate CleanMode = iota // 在前后两个正常点之间做 Hermite 插值
+	CleanKalman                       // 按定位精度加权的卡尔曼平滑，拒绝精度过差的定位
+)
+
+var cleanModeNames = []string{"interpolate", "kalman"}
+
+func (m CleanMode) String() string {
+	if int(m) < len(cleanModeNames) {
+		return cleanModeNames[m]
+	}
+	return fmt.Sprintf("CleanMode(%d)", uint8(m))
+}
+
+// MarshalText 与 UnmarshalText 使 CleanMode 可直接用作命令行参数与 JSON 配置
+func (m CleanMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *CleanMode) UnmarshalText(text []byte) error {
+	for i, name := range cleanModeNames {
+		if string(text) == name {
+			*m = CleanMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("未知的清洗方式: %s，可选 interpolate 或 kalman", text)
+}
+
+// DefaultUERE HDOP 换算为水平误差时使用的用户等效测距误差（米）
+const DefaultUERE = 5.0
+
+// KalmanOptions 卡尔曼平滑的参数，零值字段使用默认值
+type KalmanOptions struct {
+	MaxError float64 // 水平误差（米）超过该值的定位被拒绝并由平滑结果代替，0 表示不按精度拒绝
+	UERE     float64 // HDOP 乘以该值（米）即为水平误差，默认 DefaultUERE
+	Accel    float64 // 过程噪声，即加速度的标准差（米/秒²），默认 2
+}
+
+func (o KalmanOptions) withDefaults() KalmanOptions {
+	if o.UERE <= 0 {
+		o.UERE = DefaultUERE
+	}
+	if o.Accel <= 0 {
+		o.Accel = 2
+	}
+	return o
+}
+
+// HorizontalError 点的水平误差（米）：优先使用设备记录的精度，其次为 HDOP*uere；
+// 都没有记录时 ok 为 false
+func HorizontalError(p Point, uere float64) (sigma float64, ok bool) {
+	if p.Attrs.Has(AttrAccuracy) {
+		return p.Attrs.Accuracy, true
+	}
+	if hdop, ok := p.HDOP(); ok {
+		return hdop * uere, true
+	}
+	return 0, false
+}
+
+// 单个坐标轴上的匀速模型：状态为位置与速度，两个坐标轴的协方差相同
+type kalmanState struct {
+	x, v          [2]float64 // 东、北两个方向的位置与速度
+	pxx, pxv, pvv float64    // 协方差矩阵 [[pxx, pxv], [pxv, pvv]]
+}
+
+// 按时间间隔 dt 预测，q 为加速度方差
+func (s kalmanState) predict(dt, q float64) kalmanState {
+	for i := range s.x {
+		s.x[i] += s.v[i] * dt
+	}
+	pxx := s.pxx + 2*dt*s.pxv + dt*dt*s.pvv + q*dt*dt*dt/3
+	pxv := s.pxv + dt*s.pvv + q*dt*dt/2
+	pvv := s.pvv + q*dt
+	s.pxx, s.pxv, s.pvv = pxx, pxv, pvv
+	return s
+}
+
+// 用位置观测 z 更新，r 为观测方差
+func (s kalmanState) update(z [2]float64, r float64) kalmanState {
+	sInv := 1 / (s.pxx + r)
+	kx, kv := s.pxx*sInv, s.pxv*sInv
+	for i := range s.x {
+		innovation := z[i] - s.x[i]
+		s.x[i] += kx * innovation
+		s.v[i] += kv * innovation
+	}
+	pxx := (1 - kx) * s.pxx
+	pxv := (1 - kx) * s.pxv
+	pvv := s.pvv - kv*s.pxv
+	s.pxx, s.pxv, s.pvv = pxx, pxv, pvv
+	return s
+}
+
+// 相邻两点的时间间隔（秒），没有定位时间时与速度检测一样按 1 秒计
+func stepSeconds(a, b Point) float64 {
+	if !a.Time.IsZero() && !b.Time.IsZero() && b.Time.After(a.Time) {
+		return b.Time.Sub(a.Time).Seconds()
+	}
+	return 1
+}
+
+// 观测与预测之差按协方差归一化后的平方超过该值时拒绝该观测（自由度为 2 的卡方分布 99.9% 分位数）
+const innovationGate = 13.8
+
+// 对整个数据块（含重叠部分）做前向滤波与 RTS 反向平滑，返回各点平滑后的经纬度。
+// rejected 为 true 的点不作为观测，只由前后的正常定位推算；正常定位按水平误差加权，
+// 没有精度记录的点视为 HDOP 为 1。与预测相差远超其水平误差的定位同样不作为观测，
+// 在 gated 中标记。没有可用观测时 ok 为 false
+func kalmanSmooth(points []Point, rejected []bool, opts KalmanOptions) (smoothed [][2]float64, gated []bool, ok bool) {
+	first := -1
+	for i := range points {
+		if !rejected[i] {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return nil, nil, false
+	}
+
+	proj := Equirectangular{Lon0: points[first].Longitude, Lat0: points[first].Latitude}
+	q := opts.Accel * opts.Accel
+	n := len(points)
+	predicted := make([]kalmanState, n)
+	filtered := make([]kalmanState, n)
+	dts := make([]float64, n)
+	gated = make([]bool, n)
+
+	// 初始状态取第一个可用观测的位置，协方差很大，由观测决定
+	state := kalmanState{pxx: 1e8, pvv: 1e4}
+	for i, p := range points {
+		if i > 0 {
+			dts[i] = stepSeconds(points[i-1], p)
+			state = state.predict(dts[i], q)
+		}
+		predicted[i] = state
+		if !rejected[i] {
+			sigma, ok := HorizontalError(p, opts.UERE)
+			if !ok {
+				sigma = opts.UERE
+			}
+			sigma = max(sigma, 0.1) // 避免误差为 0 时协方差退化
+			var z [2]float64
+			z[0], z[1] = proj.Forward(p.Longitude, p.Latitude)
+			dx, dy := z[0]-state.x[0], z[1]-state.x[1]
+			if (dx*dx+dy*dy)/(state.pxx+sigma*sigma) > innovationGate {
+				gated[i] = true
+			} else {
+				state = state.update(z, sigma*sigma)
+			}
+		}
+		filtered[i] = state
+	}
+
+	// RTS 平滑：smoothed[k] = filtered[k] + C (smoothed[k+1] - predicted[k+1])，C = P⁺ Fᵀ (P⁻)⁻¹
+	result := make([]kalmanState, n)
+	result[n-1] = filtered[n-1]
+	for k := n - 2; k >= 0; k-- {
+		f, pNext, dt := filtered[k], predicted[k+1], dts[k+1]
+		det := pNext.pxx*pNext.pvv - pNext.pxv*pNext.pxv
+		if det == 0 {
+			result[k] = f
+			continue
+		}
+		// P⁺ Fᵀ，F = [[1, dt], [0, 1]]
+		a11, a12 := f.pxx+dt*f.pxv, f.pxv
+		a21, a22 := f.pxv+dt*f.pvv, f.pvv
+		// 乘以 (P⁻)⁻¹
+		i11, i12, i22 := pNext.pvv/det, -pNext.pxv/det, pNext.pxx/det
+		c11, c12 := a11*i11+a12*i12, a11*i12+a12*i22
+		c21, c22 := a21*i11+a22*i12, a21*i12+a22*i22
+
+		s := f
+		for axis := range s.x {
+			dx := result[k+1].x[axis] - pNext.x[axis]
+			dv := result[k+1].v[axis] - pNext.v[axis]
+			s.x[axis] += c11*dx + c12*dv
+			s.v[axis] += c21*dx + c22*dv
+		}
+		result[k] = s
+	}
+
+	smoothed = make([][2]float64, n)
+	for i, s := range result {
+		smoothed[i][0], smoothed[i][1] = proj.Inverse(s.x[0], s.x[1])
+	}
+	return smoothed, gated, true
+}
+
+// kalmanRepair 用卡尔曼平滑的结果代替数据块中被判为异常、精度过差或与前后定位不符的点，
+// 标记为 FlagSmoothed；海拔与附加属性在前后最近的正常点之间插值
+func kalmanRepair(aTask Data, isAno []bool) ([]Point, int) {
+	points := aTask.Points
+	start, end := aTask.Start, aTask.End
+	result := make([]Point, end-start+1)
+	copy(result, points[start:end+1])
+
+	smoothed, gated, ok := kalmanSmooth(points, isAno, aTask.Kalman.withDefaults())
+	if !ok {
+		log.Printf("数据块 %d 没有可用的定位，跳过平滑", aTask.TaskCode)
+		return result, 0
+	}
+	for i := range gated {
+		gated[i] = gated[i] || isAno[i]
+	}
+	isAno = gated
+
+	fixed := 0
+	for idx := start; idx <= end; idx++ {
+		if !isAno[idx] {
+			continue
+		}
+		prev, next := idx-1, idx+1
+		for prev >= 0 && isAno[prev] {
+			prev--
+		}
+		for next < len(points) && isAno[next] {
+			next++
+		}
+
+		original := points[idx]
+		p := original
+		p.Longitude, p.Latitude = smoothed[idx][0], smoothed[idx][1]
+		p.Flag = FlagSmoothed
+		p.Original = &original
+		if prev >= 0 && next < len(points) {
+			pointP, pointN := points[prev], points[next]
+			t := float64(idx-prev) / float64(next-prev)
+			if pointP.HasAltitude && pointN.HasAltitude {
+				p.Altitude = pointP.Altitude + t*(pointN.Altitude-pointP.Altitude)
+				p.HasAltitude = true
+			}
+			p.Attrs = interpolateAttrs(pointP.Attrs, pointN.Attrs, original.Attrs, t)
+		}
+		result[idx-start] = p
+		fixed++
+	}
+	return result, fixed
+}
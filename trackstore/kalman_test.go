@@ -0,0 +1,101 @@
+package trackstore
+
+import (
+	"testing"
+)
+
+// 所有点都带水平误差记录的直线轨迹
+func accuratePoints(n int, accuracy float64) []Point {
+	points := linePoints(n, 120.0)
+	for i := range points {
+		points[i].Attrs = &Attributes{Fields: AttrAccuracy, Accuracy: accuracy}
+	}
+	return points
+}
+
+func TestKalmanRejectsInaccurateFixes(t *testing.T) {
+	// 第 10 个点没有偏离，但设备报告的误差为 50 米
+	points := accuratePoints(20, 3)
+	points[10].Attrs = &Attributes{Fields: AttrAccuracy, Accuracy: 50}
+	task := Data{Points: points, Start: 0, End: len(points) - 1, Clean: CleanKalman}
+
+	if _, fixed := SpeedOutliner(task); fixed != 0 {
+		t.Fatalf("未设置误差阈值时不应修正，实际修正 %d 个", fixed)
+	}
+	task.Kalman.MaxError = 20
+	result, fixed := SpeedOutliner(task)
+	if fixed != 1 || result[10].Flag != FlagSmoothed || result[10].Original == nil {
+		t.Fatalf("误差超过阈值的点应被平滑结果代替: %d %+v", fixed, result[10])
+	}
+	if d := Haversine(result[10], linePoints(20, 120.0)[10]); d > 1 {
+		t.Errorf("平滑结果应在直线上，偏离 %.2f 米", d)
+	}
+	if !result[10].Attrs.Has(AttrAccuracy) || result[10].Attrs.Accuracy != 50 {
+		t.Errorf("修正后的点应保留水平误差记录: %+v", result[10].Attrs)
+	}
+}
+
+func TestKalmanAnchorsOnGoodFixes(t *testing.T) {
+	// 第 10 个点偏离约 110 米，其余点精度很高
+	truth := linePoints(20, 120.0)
+	points := accuratePoints(20, 2)
+	points[10].Latitude += 0.001
+	task := Data{Points: points, Start: 0, End: len(points) - 1, Clean: CleanKalman}
+
+	result, fixed := SpeedOutliner(task)
+	if fixed != 1 || result[10].Flag != FlagSmoothed {
+		t.Fatalf("只应平滑偏离的点: %d %+v", fixed, result[10])
+	}
+	for _, i := range []int{9, 11} {
+		if result[i] != points[i] {
+			t.Errorf("偏离点两侧的第 %d 个点不应被修改: %+v", i, result[i])
+		}
+	}
+	if d := Haversine(result[10], truth[10]); d > 1 {
+		t.Errorf("修正后的点应由前后的正常定位决定，偏离 %.2f 米", d)
+	}
+	for i, p := range result {
+		if p.Flag == FlagOriginal && p != points[i] {
+			t.Errorf("正常定位不应被修改: %d %+v", i, p)
+		}
+	}
+}
+
+func TestKalmanSmoothWeights(t *testing.T) {
+	truth := linePoints(11, 120.0)
+	smoothAt := func(accuracy float64) float64 {
+		points := accuratePoints(11, 2)
+		points[5].Latitude += 0.00002
+		points[5].Attrs = &Attributes{Fields: AttrAccuracy, Accuracy: accuracy}
+		smoothed, _, ok := kalmanSmooth(points, make([]bool, len(points)), KalmanOptions{}.withDefaults())
+		if !ok {
+			t.Fatalf("应有可用的观测")
+		}
+		return Haversine(Point{Longitude: smoothed[5][0], Latitude: smoothed[5][1]}, truth[5])
+	}
+	// 误差大的定位对平滑结果的影响小
+	if good, bad := smoothAt(1), smoothAt(100); bad >= good/4 {
+		t.Errorf("误差 100 米的定位偏离 %.2f 米，应远小于误差 1 米时的 %.2f 米", bad, good)
+	}
+
+	// 与前后定位相差远超报告误差的点不作为观测
+	points := accuratePoints(11, 2)
+	points[5].Latitude += 0.001
+	if _, gated, _ := kalmanSmooth(points, make([]bool, len(points)), KalmanOptions{}.withDefaults()); !gated[5] {
+		t.Errorf("偏离约 110 米的定位应被拒绝")
+	}
+
+	if _, _, ok := kalmanSmooth(truth[:3], []bool{true, true, true}, KalmanOptions{}.withDefaults()); ok {
+		t.Errorf("全部被拒绝时不应返回平滑结果")
+	}
+}
+
+func TestCleanModeText(t *testing.T) {
+	var m CleanMode
+	if err := m.UnmarshalText([]byte("kalman")); err != nil || m != CleanKalman {
+		t.Errorf("应解析为 kalman: %v %v", m, err)
+	}
+	if err := m.UnmarshalText([]byte("median")); err == nil {
+		t.Errorf("未知的清洗方式应报错")
+	}
+}
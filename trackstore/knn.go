@@ -0,0 +1,86 @@
+package trackstore
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+// knnInitialRadius 第一圈搜索半径（米），之后每圈加倍
+const knnInitialRadius = 100.0
+
+// Neighbor KNN 查询结果中的一个点
+type Neighbor struct {
+	Point      Point
+	Distance   float64 // 到查询点的距离（米）
+	Trajectory string
+	TaskIdx    int // 所在数据块
+	Index      int // 在数据块中的位置
+}
+
+// 查询点到数据块外包矩形的最近距离
+func boxDistance(p, min, max Point, distance Metric) float64 {
+	nearest := Point{
+		Longitude: math.Max(min.Longitude, math.Min(p.Longitude, max.Longitude)),
+		Latitude:  math.Max(min.Latitude, math.Min(p.Latitude, max.Latitude)),
+	}
+	return distance(p, nearest)
+}
+
+// Nearest 返回距离点 p 最近的 k 个点，按距离从近到远排列。
+// 以 p 为圆心逐圈扩大搜索半径，只读取外包矩形与当前圈相交的数据块；
+// 已找到 k 个点且第 k 个点在当前圈内时，圈外的数据块不可能更近，搜索结束。
+func (s *Store) Nearest(ctx context.Context, p Point, k int) ([]Neighbor, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+
+	type candidate struct {
+		meta ChunkMeta
+		dist float64
+	}
+	distance := s.opts.Distance.orDefault()
+	metas := s.index.Metas()
+	candidates := make([]candidate, len(metas))
+	for i, meta := range metas {
+		candidates[i] = candidate{meta, boxDistance(p, meta.Min, meta.Max, distance)}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].dist < candidates[j].dist
+	})
+
+	var found []Neighbor
+	next := 0
+	for radius := knnInitialRadius; next < len(candidates); radius *= 2 {
+		for ; next < len(candidates) && candidates[next].dist <= radius; next++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			meta := candidates[next].meta
+			points, err := s.readChunk(meta.TaskIdx)
+			if err != nil {
+				return nil, err
+			}
+			for i, pt := range points {
+				found = append(found, Neighbor{
+					Point:      pt,
+					Distance:   distance(p, pt),
+					Trajectory: meta.Trajectory,
+					TaskIdx:    meta.TaskIdx,
+					Index:      i,
+				})
+			}
+		}
+
+		sort.SliceStable(found, func(i, j int) bool {
+			return found[i].Distance < found[j].Distance
+		})
+		if len(found) > k {
+			found = found[:k]
+		}
+		if len(found) == k && found[k-1].Distance <= radius {
+			break
+		}
+	}
+	return found, nil
+}
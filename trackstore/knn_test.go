@@ -0,0 +1,59 @@
+package trackstore
+
+import (
+	"context"
+	"sort"
+	"testing"
+)
+
+func TestNearest(t *testing.T) {
+	store, err := Open(t.TempDir(), DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+
+	if _, err := store.Append(context.Background(), "a", linePoints(100, 120.0)); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if _, err := store.Append(context.Background(), "b", linePoints(100, 125.0)); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	// 逐点计算距离作为对照
+	target := Point{Longitude: 120.0151, Latitude: 30.0001}
+	var all []float64
+	for p, err := range store.Query(context.Background(), Query{}) {
+		if err != nil {
+			t.Fatalf("查询失败: %v", err)
+		}
+		all = append(all, Haversine(target, p))
+	}
+	sort.Float64s(all)
+
+	for _, k := range []int{1, 5, 20, len(all) + 10} {
+		got, err := store.Nearest(context.Background(), target, k)
+		if err != nil {
+			t.Fatalf("k=%d 查询失败: %v", k, err)
+		}
+		want := all[:min(k, len(all))]
+		if len(got) != len(want) {
+			t.Fatalf("k=%d 期望 %d 个点，实际 %d 个", k, len(want), len(got))
+		}
+		for i, n := range got {
+			if n.Distance != want[i] {
+				t.Errorf("k=%d 第 %d 个点距离期望 %f，实际 %f", k, i, want[i], n.Distance)
+			}
+		}
+	}
+
+	got, _ := store.Nearest(context.Background(), target, 1)
+	n := got[0]
+	points, err := ReadChunk(store.Dir(), n.TaskIdx)
+	if err != nil {
+		t.Fatalf("读取数据块失败: %v", err)
+	}
+	if n.Trajectory != "a" || points[n.Index] != n.Point {
+		t.Errorf("最近点的轨迹或位置不正确: %+v", n)
+	}
+}
@@ -0,0 +1,22 @@
+package trackstore
+
+import "math"
+
+// Web 墨卡托投影的纬度范围，超出的纬度按边界处理
+const maxMercatorLatitude = 85.05112878
+
+// EPSG:3857 坐标的取值范围为 [-mercatorHalfWorld, mercatorHalfWorld]
+const mercatorHalfWorld = wgs84A * math.Pi
+
+// Mercator 将经纬度转换为 Web 墨卡托（EPSG:3857）的归一化坐标：x、y 均在 [0, 1] 内，
+// x 自西向东增大，y 自北向南增大，与 Web 地图瓦片的行列方向一致。
+// 需要以米为单位的平面坐标时使用 WebMercator
+func Mercator(lon, lat float64) (x, y float64) {
+	mx, my := WebMercator{}.Forward(lon, lat)
+	return (mx/mercatorHalfWorld + 1) / 2, (1 - my/mercatorHalfWorld) / 2
+}
+
+// 墨卡托归一化坐标转回经纬度
+func inverseMercator(x, y float64) (lon, lat float64) {
+	return WebMercator{}.Inverse((2*x-1)*mercatorHalfWorld, (1-2*y)*mercatorHalfWorld)
+}
@@ -0,0 +1,281 @@
+package trackstore
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// 一次格式升级：将版本为 From 的存储目录升级到 From+1
+type migration struct {
+	From  int
+	Desc  string
+	Apply func(ctx context.Context, directory string) error
+}
+
+// 按版本顺序登记的升级步骤，新增格式版本时在末尾追加
+var migrations = []migration{
+	{From: 1, Desc: "为索引表和数据块文件添加格式头", Apply: migrateV1},
+	{From: 2, Desc: "轨迹点增加定位时间，数据块元信息增加所属轨迹与时间范围", Apply: migrateV2},
+	{From: 3, Desc: "轨迹点增加来源标记与修正前的位置", Apply: migrateV3},
+	{From: 4, Desc: "轨迹点增加海拔", Apply: migrateV4},
+	{From: 5, Desc: "轨迹点增加速度、航向、HDOP、卫星数等附加属性", Apply: migrateV5},
+	{From: 6, Desc: "附加属性增加设备报告的水平误差", Apply: migrateV6},
+	{From: 7, Desc: "数据块文件头记下所属轨迹与序号，供 REINDEX 恢复", Apply: migrateV7},
+}
+
+// 以索引表的格式版本作为整个存储目录的版本；没有索引表时取各数据块文件中最低的版本
+func storeVersion(directory string) (int, error) {
+	if hasIndex(directory) {
+		return fileVersion(filepath.Join(directory, "IndexTable.gob"))
+	}
+	taskIdxs, err := listChunkFiles(directory)
+	if err != nil {
+		return 0, err
+	}
+	if len(taskIdxs) == 0 {
+		return 0, fmt.Errorf("目录 %s 中既没有索引表也没有数据块文件", directory)
+	}
+	version := formatVersion
+	for _, taskIdx := range taskIdxs {
+		v, err := fileVersion(filepath.Join(directory, fmt.Sprintf("%d.gob", taskIdx)))
+		if err != nil {
+			return 0, fmt.Errorf("读取数据块 %d 失败: %v", taskIdx, err)
+		}
+		version = min(version, v)
+	}
+	return version, nil
+}
+
+func fileVersion(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	header, _, err := readHeader(file)
+	if err != nil && !errors.Is(err, errLegacyFormat) {
+		return 0, err
+	}
+	return header.Version, nil
+}
+
+// 目录中是否有索引表。索引表丢失时升级步骤只处理数据块文件，升级完成后由 Migrate 重建索引表
+func hasIndex(directory string) bool {
+	_, err := os.Stat(filepath.Join(directory, "IndexTable.gob"))
+	return err == nil
+}
+
+// 读取版本 1 的裸 gob 文件
+func decodeLegacyFile(path string, v any) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return gob.NewDecoder(file).Decode(v)
+}
+
+// 升级步骤总是按当前格式写文件，因此每一步都要能识别并跳过已是更新版本的文件。
+
+// 版本 1 -> 2：逐个重写数据块文件，最后重写索引表。
+// 索引表最后写入，中途失败时存储仍为版本 1，可以重新运行；已升级的数据块会被跳过。
+func migrateV1(ctx context.Context, directory string) error {
+	taskIdxs, err := listChunkFiles(directory)
+	if err != nil {
+		return err
+	}
+
+	metas := make(map[int]ChunkMeta, len(taskIdxs))
+	for _, taskIdx := range taskIdxs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		filePath := filepath.Join(directory, fmt.Sprintf("%d.gob", taskIdx))
+
+		var points []Point
+		if _, err := decodeVersioned(filePath, kindChunk, 2, &points); err != nil {
+			if err := decodeLegacyFile(filePath, &points); err != nil {
+				return fmt.Errorf("解码数据块 %d 失败: %v", taskIdx, err)
+			}
+			if err := encodeFile(filePath, kindChunk, points); err != nil {
+				return fmt.Errorf("重写数据块 %d 失败: %v", taskIdx, err)
+			}
+		}
+		metas[taskIdx] = newChunkMeta(taskIdx, points)
+	}
+
+	if !hasIndex(directory) {
+		return nil
+	}
+	indexTable := NewIndexTable()
+	if err := decodeLegacyFile(filepath.Join(directory, "IndexTable.gob"), indexTable); err != nil {
+		return fmt.Errorf("解码索引表失败: %v", err)
+	}
+	// 早期索引表没有数据块元信息，顺带补齐
+	if indexTable.Chunks == nil {
+		indexTable.Chunks = make(map[int]ChunkMeta)
+	}
+	for taskIdx, meta := range metas {
+		if _, ok := indexTable.Chunks[taskIdx]; !ok {
+			indexTable.Chunks[taskIdx] = meta
+		}
+	}
+	return indexTable.SerializeIndexTable(directory)
+}
+
+// 版本 2 -> 3：Point 增加 Time、ChunkMeta 增加 Trajectory/Seq/StartTime/EndTime。
+// 旧数据没有时间与轨迹信息，数据块按任务号顺序视为同一条未命名轨迹。
+func migrateV2(ctx context.Context, directory string) error {
+	taskIdxs, err := listChunkFiles(directory)
+	if err != nil {
+		return err
+	}
+
+	for _, taskIdx := range taskIdxs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		filePath := filepath.Join(directory, fmt.Sprintf("%d.gob", taskIdx))
+
+		var points []Point
+		header, err := decodeVersioned(filePath, kindChunk, 2, &points)
+		if err != nil {
+			return fmt.Errorf("解码数据块 %d 失败: %v", taskIdx, err)
+		}
+		if header.Version >= 3 {
+			continue
+		}
+		if err := encodeFile(filePath, kindChunk, points); err != nil {
+			return fmt.Errorf("重写数据块 %d 失败: %v", taskIdx, err)
+		}
+	}
+
+	if !hasIndex(directory) {
+		return nil
+	}
+	indexTable := NewIndexTable()
+	if _, err := decodeVersioned(filepath.Join(directory, "IndexTable.gob"), kindIndex, 2, indexTable); err != nil {
+		return fmt.Errorf("解码索引表失败: %v", err)
+	}
+	for taskIdx, meta := range indexTable.Chunks {
+		meta.Seq = taskIdx
+		indexTable.Chunks[taskIdx] = meta
+	}
+	return indexTable.SerializeIndexTable(directory)
+}
+
+// 版本 3 -> 4：Point 增加 Flag 与 Original。
+// 旧数据没有记录清洗修改了哪些点，全部标记为原始点；只需重写文件头。
+func migrateV3(ctx context.Context, directory string) error {
+	return rewriteHeaders(ctx, directory, 3)
+}
+
+// 版本 4 -> 5：Point 增加 Altitude 与 HasAltitude，旧数据没有海拔；只需重写文件头。
+func migrateV4(ctx context.Context, directory string) error {
+	return rewriteHeaders(ctx, directory, 4)
+}
+
+// 版本 5 -> 6：Point 增加 Attrs，旧数据没有附加属性；只需重写文件头。
+func migrateV5(ctx context.Context, directory string) error {
+	return rewriteHeaders(ctx, directory, 5)
+}
+
+// 版本 6 -> 7：Attributes 增加 Accuracy，旧数据没有该字段；只需重写文件头。
+func migrateV6(ctx context.Context, directory string) error {
+	return rewriteHeaders(ctx, directory, 6)
+}
+
+// 版本 7 -> 8：数据块文件头增加 Trajectory 与 Seq，由 rewriteHeaders 从索引表补齐。
+func migrateV7(ctx context.Context, directory string) error {
+	return rewriteHeaders(ctx, directory, 7)
+}
+
+// 数据本体的 gob 编码与旧版本兼容时，按当前版本重写全部数据块与索引表的文件头；
+// 数据块的所属轨迹与序号取自索引表，索引表丢失时保留文件头中原有的值
+func rewriteHeaders(ctx context.Context, directory string, from int) error {
+	taskIdxs, err := listChunkFiles(directory)
+	if err != nil {
+		return err
+	}
+
+	withIndex := hasIndex(directory)
+	indexTable := NewIndexTable()
+	if withIndex {
+		if _, err := decodeVersioned(filepath.Join(directory, "IndexTable.gob"), kindIndex, from, indexTable); err != nil {
+			return fmt.Errorf("解码索引表失败: %v", err)
+		}
+	}
+
+	for _, taskIdx := range taskIdxs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		filePath := filepath.Join(directory, fmt.Sprintf("%d.gob", taskIdx))
+
+		var points []Point
+		header, err := decodeVersioned(filePath, kindChunk, from, &points)
+		if err != nil {
+			return fmt.Errorf("解码数据块 %d 失败: %v", taskIdx, err)
+		}
+		if header.Version > from {
+			continue
+		}
+		if meta, ok := indexTable.Chunks[taskIdx]; ok {
+			header.Trajectory, header.Seq = meta.Trajectory, meta.Seq
+		}
+		if err := encodeChunk(filePath, points, header.Trajectory, header.Seq); err != nil {
+			return fmt.Errorf("重写数据块 %d 失败: %v", taskIdx, err)
+		}
+	}
+
+	if !withIndex {
+		return nil
+	}
+	return indexTable.SerializeIndexTable(directory)
+}
+
+// Migrate 将存储目录原地升级到当前格式版本；每个升级步骤都可以中断后重新运行。
+// 目录中只有数据块文件、没有索引表时，升级数据块后按 Reindex 重建索引表
+func Migrate(ctx context.Context, directory string) error {
+	version, err := storeVersion(directory)
+	if err != nil {
+		return fmt.Errorf("读取存储版本失败: %v", err)
+	}
+	if version > formatVersion {
+		return fmt.Errorf("存储格式版本 %d 高于当前程序支持的版本 %d", version, formatVersion)
+	}
+	if version == formatVersion && hasIndex(directory) {
+		log.Printf("存储已是最新格式版本 %d", version)
+		return nil
+	}
+
+	for _, m := range migrations {
+		if m.From != version {
+			continue
+		}
+		log.Printf("升级格式版本 %d -> %d: %s", m.From, m.From+1, m.Desc)
+		if err := m.Apply(ctx, directory); err != nil {
+			return fmt.Errorf("升级到版本 %d 失败: %w", m.From+1, err)
+		}
+		version = m.From + 1
+	}
+
+	if version != formatVersion {
+		return fmt.Errorf("缺少从版本 %d 开始的升级步骤", version)
+	}
+	if !hasIndex(directory) {
+		log.Printf("目录中没有索引表，根据数据块文件重建")
+		if err := Reindex(ctx, directory, DefaultWorkers()); err != nil {
+			return fmt.Errorf("重建索引表失败: %v", err)
+		}
+	}
+	log.Printf("升级完成，当前格式版本 %d", version)
+	return nil
+}
@@ -0,0 +1,155 @@
+package trackstore
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// 按指定的旧版本写文件，模拟旧版本程序写入的目录
+func writeVersioned(t *testing.T, path, kind string, version int, v any) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	file.Write([]byte(formatMagic))
+	encoder := gob.NewEncoder(file)
+	header := FileHeader{Version: version, Kind: kind, Tool: "TrackHelper old", Created: time.Now()}
+	if err := encoder.Encode(header); err != nil {
+		t.Fatal(err)
+	}
+	if err := encoder.Encode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateWithoutIndex(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir, DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	if _, err := store.Append(context.Background(), "a", linePoints(50, 120.0)); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	chunks := len(store.Index().Chunks)
+	store.Close()
+
+	// 数据块降为版本 6，索引表丢失
+	taskIdxs, _ := listChunkFiles(dir)
+	for _, taskIdx := range taskIdxs {
+		points, err := ReadChunk(dir, taskIdx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeVersioned(t, filepath.Join(dir, fmt.Sprintf("%d.gob", taskIdx)), kindChunk, 6, points)
+	}
+	os.Remove(filepath.Join(dir, "IndexTable.gob"))
+
+	if v, err := storeVersion(dir); err != nil || v != 6 {
+		t.Fatalf("没有索引表时应按数据块判断版本: %d %v", v, err)
+	}
+	if err := Migrate(context.Background(), dir); err != nil {
+		t.Fatalf("升级失败: %v", err)
+	}
+	index, err := ReadIndexTable(dir)
+	if err != nil || len(index.Chunks) != chunks {
+		t.Fatalf("升级后应重建索引表: %v", err)
+	}
+	if v, _ := storeVersion(dir); v != formatVersion {
+		t.Errorf("升级后版本应为 %d，实际 %d", formatVersion, v)
+	}
+}
+
+// 版本 1 的文件没有格式头，直接是 gob 编码的数据；当时的 Point 只有经纬度，索引表只有 Ranges
+type legacyPoint struct {
+	Longitude, Latitude float64
+}
+
+type legacyIndexTable struct {
+	Ranges map[string]int
+}
+
+func writeLegacy(t *testing.T, path string, v any) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := gob.NewEncoder(file).Encode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateV1(t *testing.T) {
+	dir := t.TempDir()
+	// 两个数据块，各 3 个点
+	chunks := [][]legacyPoint{
+		{{120.0, 30.0}, {120.0003, 30.0}, {120.0006, 30.0}},
+		{{120.0009, 30.0}, {120.0012, 30.0}, {120.0015, 30.0}},
+	}
+	index := legacyIndexTable{Ranges: map[string]int{}}
+	for taskIdx, points := range chunks {
+		writeLegacy(t, filepath.Join(dir, fmt.Sprintf("%d.gob", taskIdx)), points)
+		first, last := points[0], points[len(points)-1]
+		index.Ranges[fmt.Sprintf("%f,%f,%f,%f", first.Longitude, first.Latitude, last.Longitude, last.Latitude)] = taskIdx
+	}
+	writeLegacy(t, filepath.Join(dir, "IndexTable.gob"), index)
+
+	if v, err := storeVersion(dir); err != nil || v != 1 {
+		t.Fatalf("没有格式头的目录应为版本 1: %d %v", v, err)
+	}
+	if err := Migrate(context.Background(), dir); err != nil {
+		t.Fatalf("升级失败: %v", err)
+	}
+	if v, _ := storeVersion(dir); v != formatVersion {
+		t.Errorf("升级后版本应为 %d，实际 %d", formatVersion, v)
+	}
+
+	store, err := Open(dir, DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开升级后的存储失败: %v", err)
+	}
+	defer store.Close()
+	metas := store.Index().Metas()
+	if len(metas) != 2 || len(store.Index().Ranges) != 2 {
+		t.Fatalf("索引表应有 2 个数据块: %+v", metas)
+	}
+	for i, meta := range metas {
+		if meta.TaskIdx != i || meta.Seq != i || meta.Count != 3 {
+			t.Errorf("第 %d 个数据块的元信息不正确: %+v", i, meta)
+		}
+	}
+
+	var got []Point
+	for p, err := range store.Query(context.Background(), Query{}) {
+		if err != nil {
+			t.Fatalf("查询失败: %v", err)
+		}
+		got = append(got, p)
+	}
+	if len(got) != 6 {
+		t.Fatalf("应查询到 6 个点，实际 %d 个", len(got))
+	}
+	for i, p := range got {
+		want := chunks[i/3][i%3]
+		if p.Longitude != want.Longitude || p.Latitude != want.Latitude || !p.Time.IsZero() || p.Flag != FlagOriginal {
+			t.Errorf("第 %d 个点不正确: %+v", i, p)
+		}
+	}
+	if p, err := store.Lookup(Point{Longitude: 120.0012, Latitude: 30.0}); err != nil || len(p) != 3 || p[0].Longitude != 120.0009 {
+		t.Errorf("升级后的 Ranges 应能找到数据块: %v %v", p, err)
+	}
+
+	// 再次升级不做任何修改
+	if err := Migrate(context.Background(), dir); err != nil {
+		t.Errorf("已是最新版本时不应报错: %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package trackstore
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Mapbox Vector Tile 2.1 编码，只实现轨迹用到的线要素与字符串、整数属性。
+// 字段号见 https://github.com/mapbox/vector-tile-spec/blob/master/2.1/vector_tile.proto
+
+const (
+	mvtTileLayers = 3
+
+	mvtLayerName     = 1
+	mvtLayerFeatures = 2
+	mvtLayerKeys     = 3
+	mvtLayerValues   = 4
+	mvtLayerExtent   = 5
+	mvtLayerVersion  = 15
+
+	mvtFeatureID       = 1
+	mvtFeatureTags     = 2
+	mvtFeatureType     = 3
+	mvtFeatureGeometry = 4
+
+	mvtValueString = 1
+	mvtValueInt    = 4
+
+	mvtLineString = 2
+
+	mvtCmdMoveTo = 1
+	mvtCmdLineTo = 2
+)
+
+// 瓦片坐标系中的整数点
+type tilePoint struct {
+	X, Y int
+}
+
+type mvtFeature struct {
+	lines [][]tilePoint
+	props []mvtProperty
+}
+
+// 属性值为 string 或 int64
+type mvtProperty struct {
+	key   string
+	value any
+}
+
+type mvtLayer struct {
+	name     string
+	extent   int
+	features []mvtFeature
+}
+
+func (l *mvtLayer) encode() []byte {
+	var keys []string
+	keyIdx := make(map[string]int)
+	var values []any
+	valueIdx := make(map[any]int)
+
+	var features [][]byte
+	for i, f := range l.features {
+		var tags []byte
+		for _, prop := range f.props {
+			k, ok := keyIdx[prop.key]
+			if !ok {
+				k = len(keys)
+				keyIdx[prop.key] = k
+				keys = append(keys, prop.key)
+			}
+			v, ok := valueIdx[prop.value]
+			if !ok {
+				v = len(values)
+				valueIdx[prop.value] = v
+				values = append(values, prop.value)
+			}
+			tags = protowire.AppendVarint(tags, uint64(k))
+			tags = protowire.AppendVarint(tags, uint64(v))
+		}
+
+		var b []byte
+		b = protowire.AppendTag(b, mvtFeatureID, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(i+1))
+		b = protowire.AppendTag(b, mvtFeatureTags, protowire.BytesType)
+		b = protowire.AppendBytes(b, tags)
+		b = protowire.AppendTag(b, mvtFeatureType, protowire.VarintType)
+		b = protowire.AppendVarint(b, mvtLineString)
+		b = protowire.AppendTag(b, mvtFeatureGeometry, protowire.BytesType)
+		b = protowire.AppendBytes(b, encodeLines(f.lines))
+		features = append(features, b)
+	}
+
+	var b []byte
+	b = protowire.AppendTag(b, mvtLayerVersion, protowire.VarintType)
+	b = protowire.AppendVarint(b, 2)
+	b = protowire.AppendTag(b, mvtLayerName, protowire.BytesType)
+	b = protowire.AppendString(b, l.name)
+	for _, f := range features {
+		b = protowire.AppendTag(b, mvtLayerFeatures, protowire.BytesType)
+		b = protowire.AppendBytes(b, f)
+	}
+	for _, k := range keys {
+		b = protowire.AppendTag(b, mvtLayerKeys, protowire.BytesType)
+		b = protowire.AppendString(b, k)
+	}
+	for _, v := range values {
+		var vb []byte
+		switch v := v.(type) {
+		case string:
+			vb = protowire.AppendTag(vb, mvtValueString, protowire.BytesType)
+			vb = protowire.AppendString(vb, v)
+		case int64:
+			vb = protowire.AppendTag(vb, mvtValueInt, protowire.VarintType)
+			vb = protowire.AppendVarint(vb, uint64(v))
+		}
+		b = protowire.AppendTag(b, mvtLayerValues, protowire.BytesType)
+		b = protowire.AppendBytes(b, vb)
+	}
+	b = protowire.AppendTag(b, mvtLayerExtent, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(l.extent))
+	return b
+}
+
+// 几何命令：每条线一个 MoveTo 加一个 LineTo，参数为相对上一个点的 zigzag 编码增量，
+// 游标在同一要素的各条线之间延续
+func encodeLines(lines [][]tilePoint) []byte {
+	var b []byte
+	var cursor tilePoint
+	delta := func(p tilePoint) {
+		b = protowire.AppendVarint(b, protowire.EncodeZigZag(int64(p.X-cursor.X)))
+		b = protowire.AppendVarint(b, protowire.EncodeZigZag(int64(p.Y-cursor.Y)))
+		cursor = p
+	}
+	for _, line := range lines {
+		b = protowire.AppendVarint(b, uint64(mvtCmdMoveTo|1<<3))
+		delta(line[0])
+		b = protowire.AppendVarint(b, uint64(mvtCmdLineTo|(len(line)-1)<<3))
+		for _, p := range line[1:] {
+			delta(p)
+		}
+	}
+	return b
+}
+
+// 编码整个瓦片，没有要素的图层不输出
+func encodeTile(layers ...*mvtLayer) []byte {
+	var b []byte
+	for _, l := range layers {
+		if len(l.features) == 0 {
+			continue
+		}
+		b = protowire.AppendTag(b, mvtTileLayers, protowire.BytesType)
+		b = protowire.AppendBytes(b, l.encode())
+	}
+	return b
+}
@@ -0,0 +1,214 @@
+package trackstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Ring 由顶点依次连接而成的闭合环，首尾顶点不必重复
+type Ring []Point
+
+// 射线法判断点是否在环内
+func (r Ring) contains(p Point) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		a, b := r[i], r[j]
+		if (a.Latitude > p.Latitude) != (b.Latitude > p.Latitude) &&
+			p.Longitude < (b.Longitude-a.Longitude)*(p.Latitude-a.Latitude)/(b.Latitude-a.Latitude)+a.Longitude {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func (r Ring) bounds() BBox {
+	var b BBox
+	for i, p := range r {
+		if i == 0 {
+			b.Min, b.Max = p, p
+			continue
+		}
+		b.Min.Longitude = math.Min(b.Min.Longitude, p.Longitude)
+		b.Min.Latitude = math.Min(b.Min.Latitude, p.Latitude)
+		b.Max.Longitude = math.Max(b.Max.Longitude, p.Longitude)
+		b.Max.Latitude = math.Max(b.Max.Latitude, p.Latitude)
+	}
+	return b
+}
+
+// Polygon 带洞的多边形：点在外环内且不在任何洞内
+type Polygon struct {
+	Outer Ring
+	Holes []Ring
+}
+
+func (pg Polygon) Contains(p Point) bool {
+	if !pg.Outer.contains(p) {
+		return false
+	}
+	for _, hole := range pg.Holes {
+		if hole.contains(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// 外环与矩形相交：矩形角点在环内、环顶点在矩形内或边相交。洞不参与粗筛。
+func (pg Polygon) Intersects(min, max Point) bool {
+	if len(pg.Outer) < 3 || !pg.Outer.bounds().Intersects(min, max) {
+		return false
+	}
+	box := BBox{Min: min, Max: max}
+	corners := []Point{
+		min,
+		{Longitude: max.Longitude, Latitude: min.Latitude},
+		max,
+		{Longitude: min.Longitude, Latitude: max.Latitude},
+	}
+	for _, c := range corners {
+		if pg.Outer.contains(c) {
+			return true
+		}
+	}
+	for i, j := 0, len(pg.Outer)-1; i < len(pg.Outer); j, i = i, i+1 {
+		if box.Contains(pg.Outer[i]) {
+			return true
+		}
+		for k := range corners {
+			if segmentsIntersect(pg.Outer[j], pg.Outer[i], corners[k], corners[(k+1)%4]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func cross(o, a, b Point) float64 {
+	return (a.Longitude-o.Longitude)*(b.Latitude-o.Latitude) - (a.Latitude-o.Latitude)*(b.Longitude-o.Longitude)
+}
+
+// 线段 p1p2 与 p3p4 是否相交（含端点接触）
+func segmentsIntersect(p1, p2, p3, p4 Point) bool {
+	d1, d2 := cross(p3, p4, p1), cross(p3, p4, p2)
+	d3, d4 := cross(p1, p2, p3), cross(p1, p2, p4)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	onSegment := func(a, b, p Point) bool {
+		return math.Min(a.Longitude, b.Longitude) <= p.Longitude && p.Longitude <= math.Max(a.Longitude, b.Longitude) &&
+			math.Min(a.Latitude, b.Latitude) <= p.Latitude && p.Latitude <= math.Max(a.Latitude, b.Latitude)
+	}
+	return (d1 == 0 && onSegment(p3, p4, p1)) || (d2 == 0 && onSegment(p3, p4, p2)) ||
+		(d3 == 0 && onSegment(p1, p2, p3)) || (d4 == 0 && onSegment(p1, p2, p4))
+}
+
+// ParsePolygon 解析 WKT（POLYGON ((...), (...))）或 GeoJSON（Polygon 几何对象或其 Feature）格式的多边形
+func ParsePolygon(s string) (Polygon, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "{") {
+		return parseGeoJSONPolygon([]byte(s))
+	}
+	return parseWKTPolygon(s)
+}
+
+// 去掉与首顶点重复的尾顶点，并检查顶点数
+func newRing(points []Point) (Ring, error) {
+	if n := len(points); n > 1 && points[0] == points[n-1] {
+		points = points[:n-1]
+	}
+	if len(points) < 3 {
+		return nil, fmt.Errorf("多边形的环至少需要 3 个顶点")
+	}
+	return Ring(points), nil
+}
+
+func newPolygon(rings []Ring) (Polygon, error) {
+	if len(rings) == 0 {
+		return Polygon{}, fmt.Errorf("多边形没有外环")
+	}
+	return Polygon{Outer: rings[0], Holes: rings[1:]}, nil
+}
+
+func parseWKTPolygon(s string) (Polygon, error) {
+	upper := strings.ToUpper(s)
+	if !strings.HasPrefix(upper, "POLYGON") {
+		return Polygon{}, fmt.Errorf("只支持 POLYGON 类型的 WKT: %.20s", s)
+	}
+	body := strings.TrimSpace(s[len("POLYGON"):])
+	if !strings.HasPrefix(body, "(") || !strings.HasSuffix(body, ")") {
+		return Polygon{}, fmt.Errorf("WKT 缺少括号")
+	}
+	body = strings.TrimSpace(body[1 : len(body)-1])
+
+	var rings []Ring
+	for body != "" {
+		if body[0] != '(' {
+			return Polygon{}, fmt.Errorf("WKT 环应以括号开始: %.20s", body)
+		}
+		end := strings.IndexByte(body, ')')
+		if end < 0 {
+			return Polygon{}, fmt.Errorf("WKT 环缺少右括号")
+		}
+
+		var points []Point
+		for _, pair := range strings.Split(body[1:end], ",") {
+			fields := strings.Fields(pair)
+			if len(fields) < 2 {
+				return Polygon{}, fmt.Errorf("WKT 坐标格式错误: %s", pair)
+			}
+			lon, err1 := strconv.ParseFloat(fields[0], 64)
+			lat, err2 := strconv.ParseFloat(fields[1], 64)
+			if err1 != nil || err2 != nil {
+				return Polygon{}, fmt.Errorf("WKT 坐标格式错误: %s", pair)
+			}
+			points = append(points, Point{Longitude: lon, Latitude: lat})
+		}
+		ring, err := newRing(points)
+		if err != nil {
+			return Polygon{}, err
+		}
+		rings = append(rings, ring)
+
+		body = strings.TrimSpace(body[end+1:])
+		body = strings.TrimSpace(strings.TrimPrefix(body, ","))
+	}
+	return newPolygon(rings)
+}
+
+func parseGeoJSONPolygon(data []byte) (Polygon, error) {
+	var obj struct {
+		Type        string          `json:"type"`
+		Coordinates [][][]float64   `json:"coordinates"`
+		Geometry    json.RawMessage `json:"geometry"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return Polygon{}, fmt.Errorf("解析 GeoJSON 失败: %v", err)
+	}
+	if obj.Type == "Feature" {
+		return parseGeoJSONPolygon(obj.Geometry)
+	}
+	if obj.Type != "Polygon" {
+		return Polygon{}, fmt.Errorf("只支持 Polygon 类型的 GeoJSON，实际为 %q", obj.Type)
+	}
+
+	var rings []Ring
+	for _, coords := range obj.Coordinates {
+		points := make([]Point, 0, len(coords))
+		for _, c := range coords {
+			if len(c) < 2 {
+				return Polygon{}, fmt.Errorf("GeoJSON 坐标至少需要经度和纬度")
+			}
+			points = append(points, Point{Longitude: c[0], Latitude: c[1]})
+		}
+		ring, err := newRing(points)
+		if err != nil {
+			return Polygon{}, err
+		}
+		rings = append(rings, ring)
+	}
+	return newPolygon(rings)
+}
@@ -0,0 +1,48 @@
+package trackstore
+
+import "testing"
+
+func TestParsePolygon(t *testing.T) {
+	inputs := []string{
+		"POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0), (4 4, 6 4, 6 6, 4 6, 4 4))",
+		`{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[4,4],[6,4],[6,6],[4,6],[4,4]]]}`,
+		`{"type":"Feature","properties":{},"geometry":{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10]],[[4,4],[6,4],[6,6],[4,6]]]}}`,
+	}
+	for _, input := range inputs {
+		pg, err := ParsePolygon(input)
+		if err != nil {
+			t.Fatalf("解析 %s 失败: %v", input, err)
+		}
+		if len(pg.Outer) != 4 || len(pg.Holes) != 1 {
+			t.Fatalf("解析 %s 结果不正确: %+v", input, pg)
+		}
+
+		cases := []struct {
+			p    Point
+			want bool
+		}{
+			{Point{Longitude: 1, Latitude: 1}, true},
+			{Point{Longitude: 5, Latitude: 5}, false}, // 在洞内
+			{Point{Longitude: 11, Latitude: 5}, false},
+		}
+		for _, c := range cases {
+			if got := pg.Contains(c.p); got != c.want {
+				t.Errorf("%s: Contains(%v) = %v，期望 %v", input, c.p, got, c.want)
+			}
+		}
+
+		// 与外环相交但角点都不在多边形内的矩形
+		if !pg.Intersects(Point{Longitude: -1, Latitude: 2}, Point{Longitude: 11, Latitude: 3}) {
+			t.Errorf("%s: 穿过多边形的矩形应判定为相交", input)
+		}
+		if pg.Intersects(Point{Longitude: 20, Latitude: 20}, Point{Longitude: 21, Latitude: 21}) {
+			t.Errorf("%s: 远离多边形的矩形不应判定为相交", input)
+		}
+	}
+
+	for _, bad := range []string{"POINT (1 2)", "POLYGON ((0 0, 1 1))", `{"type":"LineString"}`} {
+		if _, err := ParsePolygon(bad); err == nil {
+			t.Errorf("解析 %s 应失败", bad)
+		}
+	}
+}
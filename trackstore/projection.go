@@ -0,0 +1,246 @@
+package trackstore
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WGS84 椭球参数
+const (
+	wgs84A = 6378137.0
+	wgs84F = 1 / 298.257223563
+)
+
+// Projection 经纬度（度）与平面坐标（米，x 向东、y 向北）之间的转换
+type Projection interface {
+	Forward(lon, lat float64) (x, y float64)
+	Inverse(x, y float64) (lon, lat float64)
+	Name() string
+}
+
+// WebMercator EPSG:3857 球面墨卡托投影，纬度超出 ±85.0511° 时按边界处理
+type WebMercator struct{}
+
+func (WebMercator) Forward(lon, lat float64) (x, y float64) {
+	lat = math.Max(-maxMercatorLatitude, math.Min(lat, maxMercatorLatitude))
+	x = wgs84A * lon * math.Pi / 180
+	y = wgs84A * math.Log(math.Tan(math.Pi/4+lat*math.Pi/360))
+	return
+}
+
+func (WebMercator) Inverse(x, y float64) (lon, lat float64) {
+	lon = x / wgs84A * 180 / math.Pi
+	lat = math.Atan(math.Sinh(y/wgs84A)) * 180 / math.Pi
+	return
+}
+
+func (WebMercator) Name() string { return "EPSG:3857" }
+
+// Equirectangular 等距圆柱投影：以 Lat0 处的纬线长度为准等比缩放经度，
+// 在 (Lon0, Lat0) 附近几十公里内误差很小，计算量最低
+type Equirectangular struct {
+	Lon0, Lat0 float64
+}
+
+func (e Equirectangular) Forward(lon, lat float64) (x, y float64) {
+	x = wgs84A * (lon - e.Lon0) * math.Pi / 180 * math.Cos(e.Lat0*math.Pi/180)
+	y = wgs84A * (lat - e.Lat0) * math.Pi / 180
+	return
+}
+
+func (e Equirectangular) Inverse(x, y float64) (lon, lat float64) {
+	lon = e.Lon0 + x/(wgs84A*math.Cos(e.Lat0*math.Pi/180))*180/math.Pi
+	lat = e.Lat0 + y/wgs84A*180/math.Pi
+	return
+}
+
+func (e Equirectangular) Name() string { return "equirectangular" }
+
+// UTM WGS84 椭球上的通用横轴墨卡托投影，使用 Krüger 级数展开，带内误差在毫米级
+type UTM struct {
+	Zone  int // 1 到 60
+	North bool
+}
+
+// UTMZone 返回经纬度所在的 UTM 带号，包含挪威西南部与斯瓦尔巴群岛的例外分带
+func UTMZone(lon, lat float64) int {
+	zone := int(math.Floor((lon+180)/6)) + 1
+	switch {
+	case lat >= 56 && lat < 64 && lon >= 3 && lon < 12:
+		zone = 32
+	case lat >= 72 && lat < 84 && lon >= 0 && lon < 42:
+		switch {
+		case lon < 9:
+			zone = 31
+		case lon < 21:
+			zone = 33
+		case lon < 33:
+			zone = 35
+		default:
+			zone = 37
+		}
+	}
+	return min(max(zone, 1), 60)
+}
+
+// NewUTM 返回经纬度所在分带的 UTM 投影
+func NewUTM(lon, lat float64) UTM {
+	return UTM{Zone: UTMZone(lon, lat), North: lat >= 0}
+}
+
+const (
+	utmK0       = 0.9996
+	utmEasting  = 500000.0
+	utmNorthing = 10000000.0 // 南半球的北向偏移
+)
+
+// Krüger 级数的系数，由第三扁率 n 计算
+var utmA, utmAlpha, utmBeta, utmDelta = func() (float64, [3]float64, [3]float64, [3]float64) {
+	n := wgs84F / (2 - wgs84F)
+	n2, n3 := n*n, n*n*n
+	a := wgs84A / (1 + n) * (1 + n2/4 + n2*n2/64)
+	alpha := [3]float64{n/2 - 2*n2/3 + 5*n3/16, 13*n2/48 - 3*n3/5, 61 * n3 / 240}
+	beta := [3]float64{n/2 - 2*n2/3 + 37*n3/96, n2/48 + n3/15, 17 * n3 / 480}
+	delta := [3]float64{2*n - 2*n2/3 - 2*n3, 7*n2/3 - 8*n3/5, 56 * n3 / 15}
+	return a, alpha, beta, delta
+}()
+
+func (u UTM) centralMeridian() float64 {
+	return float64(u.Zone-1)*6 - 180 + 3
+}
+
+func (u UTM) Forward(lon, lat float64) (x, y float64) {
+	n := wgs84F / (2 - wgs84F)
+	c := 2 * math.Sqrt(n) / (1 + n)
+	phi := lat * math.Pi / 180
+	dl := (lon - u.centralMeridian()) * math.Pi / 180
+
+	t := math.Sinh(math.Atanh(math.Sin(phi)) - c*math.Atanh(c*math.Sin(phi)))
+	xi := math.Atan2(t, math.Cos(dl))
+	eta := math.Atanh(math.Sin(dl) / math.Sqrt(1+t*t))
+
+	e, nn := eta, xi
+	for j, a := range utmAlpha {
+		k := 2 * float64(j+1)
+		e += a * math.Cos(k*xi) * math.Sinh(k*eta)
+		nn += a * math.Sin(k*xi) * math.Cosh(k*eta)
+	}
+	x = utmEasting + utmK0*utmA*e
+	y = utmK0 * utmA * nn
+	if !u.North {
+		y += utmNorthing
+	}
+	return
+}
+
+func (u UTM) Inverse(x, y float64) (lon, lat float64) {
+	if !u.North {
+		y -= utmNorthing
+	}
+	xi := y / (utmK0 * utmA)
+	eta := (x - utmEasting) / (utmK0 * utmA)
+
+	xi1, eta1 := xi, eta
+	for j, b := range utmBeta {
+		k := 2 * float64(j+1)
+		xi1 -= b * math.Sin(k*xi) * math.Cosh(k*eta)
+		eta1 -= b * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+	chi := math.Asin(math.Sin(xi1) / math.Cosh(eta1))
+	phi := chi
+	for j, d := range utmDelta {
+		phi += d * math.Sin(2*float64(j+1)*chi)
+	}
+	lon = u.centralMeridian() + math.Atan2(math.Sinh(eta1), math.Cos(xi1))*180/math.Pi
+	lat = phi * 180 / math.Pi
+	return
+}
+
+func (u UTM) Name() string {
+	hemisphere := "N"
+	if !u.North {
+		hemisphere = "S"
+	}
+	return fmt.Sprintf("UTM %d%s", u.Zone, hemisphere)
+}
+
+// ENU 以 Origin 为原点、与 WGS84 椭球相切的局部东-北-天坐标系，忽略高程
+type ENU struct {
+	Origin Point
+}
+
+// 椭球面上一点（高程为 0）的地心地固坐标
+func ecef(lon, lat float64) (x, y, z float64) {
+	e2 := wgs84F * (2 - wgs84F)
+	phi, lambda := lat*math.Pi/180, lon*math.Pi/180
+	n := wgs84A / math.Sqrt(1-e2*math.Sin(phi)*math.Sin(phi))
+	x = n * math.Cos(phi) * math.Cos(lambda)
+	y = n * math.Cos(phi) * math.Sin(lambda)
+	z = n * (1 - e2) * math.Sin(phi)
+	return
+}
+
+func (e ENU) Forward(lon, lat float64) (x, y float64) {
+	x0, y0, z0 := ecef(e.Origin.Longitude, e.Origin.Latitude)
+	x1, y1, z1 := ecef(lon, lat)
+	dx, dy, dz := x1-x0, y1-y0, z1-z0
+	phi, lambda := e.Origin.Latitude*math.Pi/180, e.Origin.Longitude*math.Pi/180
+	x = -math.Sin(lambda)*dx + math.Cos(lambda)*dy
+	y = -math.Sin(phi)*math.Cos(lambda)*dx - math.Sin(phi)*math.Sin(lambda)*dy + math.Cos(phi)*dz
+	return
+}
+
+// Inverse 求椭球面上东、北坐标为 (x, y) 的点。平面坐标丢失了天向分量，
+// 从等距圆柱投影的近似解出发，用当地的子午圈、卯酉圈曲率半径迭代修正
+func (e ENU) Inverse(x, y float64) (lon, lat float64) {
+	lon, lat = Equirectangular{e.Origin.Longitude, e.Origin.Latitude}.Inverse(x, y)
+	e2 := wgs84F * (2 - wgs84F)
+	for i := 0; i < 10; i++ {
+		fx, fy := e.Forward(lon, lat)
+		dx, dy := x-fx, y-fy
+		if math.Abs(dx) < 1e-6 && math.Abs(dy) < 1e-6 {
+			break
+		}
+		phi := lat * math.Pi / 180
+		w := 1 - e2*math.Sin(phi)*math.Sin(phi)
+		m := wgs84A * (1 - e2) / math.Pow(w, 1.5)
+		n := wgs84A / math.Sqrt(w)
+		lon += dx / (n * math.Cos(phi)) * 180 / math.Pi
+		lat += dy / m * 180 / math.Pi
+	}
+	return
+}
+
+func (e ENU) Name() string {
+	return fmt.Sprintf("ENU %.4f,%.4f", e.Origin.Longitude, e.Origin.Latitude)
+}
+
+// ProjectionNames 可供 NewProjection 使用的投影名称
+var ProjectionNames = []string{"webmercator", "equirectangular", "utm", "enu"}
+
+// NewProjection 按名称创建投影，名称不区分大小写：
+// webmercator（或 3857、epsg:3857）、equirectangular、utm、utm:<带号><n|s>（如 utm:50n）、enu。
+// 等距圆柱投影、ENU 与自动分带的 UTM 以 origin 为参考点，通常取数据范围的中心
+func NewProjection(name string, origin Point) (Projection, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch name {
+	case "webmercator", "3857", "epsg:3857":
+		return WebMercator{}, nil
+	case "equirectangular":
+		return Equirectangular{origin.Longitude, origin.Latitude}, nil
+	case "utm":
+		return NewUTM(origin.Longitude, origin.Latitude), nil
+	case "enu":
+		return ENU{Origin: Point{Longitude: origin.Longitude, Latitude: origin.Latitude}}, nil
+	}
+	if zone, ok := strings.CutPrefix(name, "utm:"); ok && len(zone) >= 2 {
+		hemisphere := zone[len(zone)-1]
+		n, err := strconv.Atoi(zone[:len(zone)-1])
+		if err == nil && n >= 1 && n <= 60 && (hemisphere == 'n' || hemisphere == 's') {
+			return UTM{Zone: n, North: hemisphere == 'n'}, nil
+		}
+	}
+	return nil, fmt.Errorf("未知的投影: %s，可选 %s 或 utm:<带号><n|s>", name, strings.Join(ProjectionNames, "、"))
+}
@@ -0,0 +1,94 @@
+package trackstore
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProjectionRoundTrip(t *testing.T) {
+	origin := Point{Longitude: 116.3, Latitude: 39.9}
+	for _, name := range []string{"webmercator", "equirectangular", "utm", "utm:50n", "enu"} {
+		proj, err := NewProjection(name, origin)
+		if err != nil {
+			t.Fatalf("创建投影 %s 失败: %v", name, err)
+		}
+		for _, p := range []Point{origin, {Longitude: 116.5, Latitude: 40.1}, {Longitude: 115.9, Latitude: 39.5}} {
+			x, y := proj.Forward(p.Longitude, p.Latitude)
+			lon, lat := proj.Inverse(x, y)
+			if math.Abs(lon-p.Longitude) > 1e-8 || math.Abs(lat-p.Latitude) > 1e-8 {
+				t.Errorf("%s: (%f, %f) 往返后为 (%.10f, %.10f)", proj.Name(), p.Longitude, p.Latitude, lon, lat)
+			}
+		}
+	}
+
+	if _, err := NewProjection("lambert", origin); err == nil {
+		t.Errorf("未知投影应返回错误")
+	}
+	if _, err := NewProjection("utm:61n", origin); err == nil {
+		t.Errorf("带号超出范围应返回错误")
+	}
+}
+
+func TestWebMercator(t *testing.T) {
+	x, y := WebMercator{}.Forward(180, 0)
+	if math.Abs(x-20037508.342789244) > 1e-6 || y != 0 {
+		t.Errorf("经度 180 应投影到 x=20037508.34，实际 (%f, %f)", x, y)
+	}
+	// 极点按 ±85.0511° 处理，不产生无穷大
+	_, top := WebMercator{}.Forward(0, 90)
+	if math.IsInf(top, 0) || math.Abs(top-20037508.342789244) > 1 {
+		t.Errorf("北极应投影到世界上边界，实际 %f", top)
+	}
+	if x, y := Mercator(0, 90); x != 0.5 || math.Abs(y) > 1e-9 {
+		t.Errorf("归一化坐标不正确: (%f, %f)", x, y)
+	}
+}
+
+func TestUTM(t *testing.T) {
+	// 自由女神像，参考值由 Snyder 的级数公式独立计算
+	utm := NewUTM(-74.0445, 40.6892)
+	if utm.Zone != 18 || !utm.North {
+		t.Fatalf("分带不正确: %s", utm.Name())
+	}
+	x, y := utm.Forward(-74.0445, 40.6892)
+	if math.Abs(x-580735.8707) > 1e-3 || math.Abs(y-4504695.1654) > 1e-3 {
+		t.Errorf("UTM 坐标不正确: (%f, %f)", x, y)
+	}
+
+	// 中央经线上赤道处为 (500000, 0)，南半球加 10000000 的北向偏移
+	if x, y := (UTM{Zone: 31, North: true}).Forward(3, 0); math.Abs(x-500000) > 1e-6 || math.Abs(y) > 1e-6 {
+		t.Errorf("赤道与中央经线交点不正确: (%f, %f)", x, y)
+	}
+	if _, y := (UTM{Zone: 31}).Forward(3, -1e-9); math.Abs(y-10000000) > 1e-3 {
+		t.Errorf("南半球北向偏移不正确: %f", y)
+	}
+
+	if zone := UTMZone(5, 60); zone != 32 {
+		t.Errorf("挪威西南部应为 32 带，实际 %d", zone)
+	}
+	if zone := UTMZone(20, 78); zone != 33 {
+		t.Errorf("斯瓦尔巴群岛应为 33 带，实际 %d", zone)
+	}
+}
+
+func TestENU(t *testing.T) {
+	enu := ENU{Origin: Point{Longitude: 116.3, Latitude: 39.9}}
+	if x, y := enu.Forward(116.3, 39.9); math.Abs(x) > 1e-9 || math.Abs(y) > 1e-9 {
+		t.Errorf("原点应投影到 (0, 0)，实际 (%f, %f)", x, y)
+	}
+	// 按当地曲率半径向北、向东各走 1 公里，切平面上的距离相差应在厘米级
+	phi := 39.9 * math.Pi / 180
+	e2 := wgs84F * (2 - wgs84F)
+	w := 1 - e2*math.Sin(phi)*math.Sin(phi)
+	m := wgs84A * (1 - e2) / math.Pow(w, 1.5)
+	n := wgs84A / math.Sqrt(w)
+	x, y := enu.Forward(116.3, 39.9+1000/m*180/math.Pi)
+	if math.Abs(x) > 1e-6 || math.Abs(y-1000) > 0.01 {
+		t.Errorf("向北 1 公里的点不正确: (%f, %f)", x, y)
+	}
+	// 纬线向极点弯曲，在切平面上略偏北
+	x, y = enu.Forward(116.3+1000/(n*math.Cos(phi))*180/math.Pi, 39.9)
+	if math.Abs(x-1000) > 0.01 || math.Abs(y-1000*1000*math.Tan(phi)/(2*n)) > 0.01 {
+		t.Errorf("向东 1 公里的点不正确: (%f, %f)", x, y)
+	}
+}
@@ -0,0 +1,280 @@
+package trackstore
+
+import (
+	"context"
+	"iter"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Filter 空间查询条件：先用数据块的外包矩形粗筛，再逐点判断
+type Filter interface {
+	Intersects(min, max Point) bool
+	Contains(p Point) bool
+}
+
+// BBox 经纬度矩形，Min 为左下角，Max 为右上角
+type BBox struct {
+	Min Point
+	Max Point
+}
+
+func (b BBox) Intersects(min, max Point) bool {
+	return min.Longitude <= b.Max.Longitude && max.Longitude >= b.Min.Longitude &&
+		min.Latitude <= b.Max.Latitude && max.Latitude >= b.Min.Latitude
+}
+
+func (b BBox) Contains(p Point) bool {
+	return p.Longitude >= b.Min.Longitude && p.Longitude <= b.Max.Longitude &&
+		p.Latitude >= b.Min.Latitude && p.Latitude <= b.Max.Latitude
+}
+
+// Circle 以 Center 为圆心、半径 Radius 米的范围
+type Circle struct {
+	Center Point
+	Radius float64
+	Metric Metric // 为 nil 时使用存储的 Options.Distance
+}
+
+// 矩形内离圆心最近的点在半径之内即相交
+func (c Circle) Intersects(min, max Point) bool {
+	nearest := Point{
+		Longitude: math.Max(min.Longitude, math.Min(c.Center.Longitude, max.Longitude)),
+		Latitude:  math.Max(min.Latitude, math.Min(c.Center.Latitude, max.Latitude)),
+	}
+	return c.Metric.orDefault()(c.Center, nearest) <= c.Radius
+}
+
+func (c Circle) Contains(p Point) bool {
+	return c.Metric.orDefault()(c.Center, p) <= c.Radius
+}
+
+// Query 查询条件，各条件之间为“与”关系，零值条件不生效。
+// 设置了时间窗口时，没有定位时间的点不会被返回。
+type Query struct {
+	BBox       *BBox
+	Polygon    *Polygon
+	Radius     *Circle
+	Since      time.Time // 含
+	Until      time.Time // 不含
+	Trajectory string
+}
+
+// 未指定距离度量的圆形范围使用 metric
+func (q Query) filters(metric Metric) []Filter {
+	var filters []Filter
+	if q.BBox != nil {
+		filters = append(filters, *q.BBox)
+	}
+	if q.Polygon != nil {
+		filters = append(filters, *q.Polygon)
+	}
+	if q.Radius != nil {
+		circle := *q.Radius
+		if circle.Metric == nil {
+			circle.Metric = metric
+		}
+		filters = append(filters, circle)
+	}
+	return filters
+}
+
+func (q Query) hasTimeWindow() bool {
+	return !q.Since.IsZero() || !q.Until.IsZero()
+}
+
+func (q Query) matchTime(start, end time.Time) bool {
+	if !q.hasTimeWindow() {
+		return true
+	}
+	if start.IsZero() {
+		return false
+	}
+	if !q.Since.IsZero() && end.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !start.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+func (q Query) matchChunk(meta ChunkMeta, filters []Filter) bool {
+	if q.Trajectory != "" && meta.Trajectory != q.Trajectory {
+		return false
+	}
+	if !q.matchTime(meta.StartTime, meta.EndTime) {
+		return false
+	}
+	for _, f := range filters {
+		if !f.Intersects(meta.Min, meta.Max) {
+			return false
+		}
+	}
+	return true
+}
+
+func (q Query) matchPoint(p Point, filters []Filter) bool {
+	if !q.matchTime(p.Time, p.Time) {
+		return false
+	}
+	for _, f := range filters {
+		if !f.Contains(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// Chunk 查询结果中的一个数据块及其中满足条件的点
+type Chunk struct {
+	Meta   ChunkMeta
+	Points []Point
+}
+
+// Metas 返回全部数据块元信息，按轨迹、轨迹内序号排序
+func (it *IndexTable) Metas() []ChunkMeta {
+	it.mu.RLock()
+	metas := make([]ChunkMeta, 0, len(it.Chunks))
+	for _, meta := range it.Chunks {
+		metas = append(metas, meta)
+	}
+	it.mu.RUnlock()
+
+	sort.Slice(metas, func(i, j int) bool {
+		if metas[i].Trajectory != metas[j].Trajectory {
+			return metas[i].Trajectory < metas[j].Trajectory
+		}
+		if metas[i].Seq != metas[j].Seq {
+			return metas[i].Seq < metas[j].Seq
+		}
+		return metas[i].TaskIdx < metas[j].TaskIdx
+	})
+	return metas
+}
+
+// Meta 返回任务号为 taskIdx 的数据块元信息
+func (it *IndexTable) Meta(taskIdx int) (ChunkMeta, bool) {
+	it.mu.RLock()
+	defer it.mu.RUnlock()
+	meta, ok := it.Chunks[taskIdx]
+	return meta, ok
+}
+
+type loadResult struct {
+	points []Point
+	err    error
+}
+
+// QueryChunks 返回满足查询条件的数据块，按轨迹、轨迹内序号的顺序逐个产出。
+// 数据块由多个 goroutine 并发读取，已读取但尚未被消费的数据块数量有上限。
+// 读取失败的数据块以错误的形式产出，调用方可以选择继续迭代。
+func (s *Store) QueryChunks(ctx context.Context, q Query) iter.Seq2[Chunk, error] {
+	return func(yield func(Chunk, error) bool) {
+		filters := q.filters(s.opts.Distance)
+		var metas []ChunkMeta
+		for _, meta := range s.index.Metas() {
+			if q.matchChunk(meta, filters) {
+				metas = append(metas, meta)
+			}
+		}
+		if len(metas) == 0 {
+			return
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		defer cancel()
+
+		numWorker := max(s.opts.QueryWorkers, 1)
+		results := make([]chan loadResult, len(metas))
+		for i := range results {
+			results[i] = make(chan loadResult, 1)
+		}
+		jobs := make(chan int)
+		window := make(chan struct{}, 2*numWorker)
+
+		// 按顺序派发读取任务，窗口已满时等待消费
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(jobs)
+			for i := range metas {
+				select {
+				case window <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				select {
+				case jobs <- i:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		for w := 0; w < numWorker; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					points, err := s.readChunk(metas[i].TaskIdx)
+					results[i] <- loadResult{points, err}
+				}
+			}()
+		}
+
+		for i, meta := range metas {
+			var r loadResult
+			select {
+			case r = <-results[i]:
+			case <-ctx.Done():
+				yield(Chunk{}, ctx.Err())
+				return
+			}
+			<-window
+
+			if r.err != nil {
+				if !yield(Chunk{Meta: meta}, r.err) {
+					return
+				}
+				continue
+			}
+			// 数据块可能来自缓存，不能原地过滤
+			var matched []Point
+			for _, p := range r.points {
+				if q.matchPoint(p, filters) {
+					matched = append(matched, p)
+				}
+			}
+			if len(matched) == 0 {
+				continue
+			}
+			if !yield(Chunk{Meta: meta, Points: matched}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Query 逐个产出满足查询条件的点，顺序与 QueryChunks 相同
+func (s *Store) Query(ctx context.Context, q Query) iter.Seq2[Point, error] {
+	return func(yield func(Point, error) bool) {
+		for chunk, err := range s.QueryChunks(ctx, q) {
+			if err != nil {
+				if !yield(Point{}, err) {
+					return
+				}
+				continue
+			}
+			for _, p := range chunk.Points {
+				if !yield(p, nil) {
+					return
+				}
+			}
+		}
+	}
+}
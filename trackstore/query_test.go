@@ -0,0 +1,66 @@
+package trackstore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQuery(t *testing.T) {
+	store, err := Open(t.TempDir(), DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+
+	start := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	points := linePoints(100, 120.0)
+	for i := range points {
+		points[i].Time = start.Add(time.Duration(i) * time.Second)
+	}
+	if _, err := store.Append(context.Background(), "a", points); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if _, err := store.Append(context.Background(), "b", linePoints(100, 120.0)); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	count := func(q Query) int {
+		n := 0
+		for _, err := range store.Query(context.Background(), q) {
+			if err != nil {
+				t.Fatalf("查询失败: %v", err)
+			}
+			n++
+		}
+		return n
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  int
+	}{
+		{"全部", Query{}, 200},
+		{"轨迹", Query{Trajectory: "a"}, 100},
+		{"时间窗口", Query{Since: start.Add(10 * time.Second), Until: start.Add(20 * time.Second)}, 10},
+		{"矩形", Query{BBox: &BBox{Min: points[0], Max: Point{Longitude: points[9].Longitude, Latitude: 31}}}, 20},
+		{"半径", Query{Radius: &Circle{Center: points[50], Radius: 100}, Trajectory: "a"}, 7},
+		{"多边形", Query{Polygon: &Polygon{Outer: Ring{
+			{Longitude: 119.9, Latitude: 29.9},
+			{Longitude: points[4].Longitude + 0.0001, Latitude: 29.9},
+			{Longitude: points[4].Longitude + 0.0001, Latitude: 30.1},
+			{Longitude: 119.9, Latitude: 30.1},
+		}}}, 10},
+	}
+	for _, tt := range tests {
+		if got := count(tt.query); got != tt.want {
+			t.Errorf("%s: 期望 %d 个点，实际 %d 个", tt.name, tt.want, got)
+		}
+	}
+
+	// 提前结束迭代不应阻塞
+	for range store.Query(context.Background(), Query{}) {
+		break
+	}
+}
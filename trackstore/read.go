@@ -0,0 +1,37 @@
+package trackstore
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// ReadIndexTable 读取目录中的 IndexTable.gob
+func ReadIndexTable(directory string) (*IndexTable, error) {
+	filePath := filepath.Join(directory, "IndexTable.gob")
+
+	var indexTable IndexTable
+	if _, err := decodeFile(filePath, kindIndex, &indexTable); err != nil {
+		return nil, fmt.Errorf("索引表损毁: %v", err)
+	}
+
+	return &indexTable, nil
+}
+
+// ReadChunk 读取目录中任务号为 taskIdx 的数据块文件
+func ReadChunk(directory string, taskIdx int) ([]Point, error) {
+	points, _, err := readChunkFile(directory, taskIdx)
+	return points, err
+}
+
+// 读取数据块文件及其文件头，文件头中有所属轨迹与序号
+func readChunkFile(directory string, taskIdx int) ([]Point, FileHeader, error) {
+	filePath := filepath.Join(directory, fmt.Sprintf("%d.gob", taskIdx))
+
+	var points []Point
+	header, err := decodeFile(filePath, kindChunk, &points)
+	if err != nil {
+		return nil, header, fmt.Errorf("读取文件 %s 失败: %v", filePath, err)
+	}
+
+	return points, header, nil
+}
@@ -0,0 +1,105 @@
+package trackstore
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 扫描目录中的数据块文件 <n>.gob，返回按序排列的任务号
+func listChunkFiles(directory string) ([]int, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("读取目录失败: %v", err)
+	}
+
+	var taskIdxs []int
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".gob") {
+			continue
+		}
+		taskIdx, err := strconv.Atoi(strings.TrimSuffix(name, ".gob"))
+		if err != nil {
+			// IndexTable.gob 等非数据块文件
+			continue
+		}
+		taskIdxs = append(taskIdxs, taskIdx)
+	}
+	sort.Ints(taskIdxs)
+	return taskIdxs, nil
+}
+
+// 读取数据块文件并将其外包矩形登记到索引表
+func worker_reindex(ctx context.Context, id int, tasks <-chan int, indexTable *IndexTable, directory string, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for taskIdx := range tasks {
+		if ctx.Err() != nil {
+			return
+		}
+		points, header, err := readChunkFile(directory, taskIdx)
+		if err != nil {
+			log.Printf("错误: 数据块 %d 损毁，已跳过: %v", taskIdx, err)
+			continue
+		}
+		if len(points) == 0 {
+			log.Printf("数据块 %d 为空，已跳过", taskIdx)
+			continue
+		}
+		meta := newChunkMeta(taskIdx, points)
+		meta.Trajectory, meta.Seq = header.Trajectory, header.Seq
+		indexTable.AddChunk(meta)
+		log.Printf("Worker %d 完成数据块 %d 的索引，共 %d 个点", id, taskIdx, len(points))
+	}
+}
+
+// Reindex 根据目录中的数据块文件重建 IndexTable.gob；中断时保留原索引表不动
+func Reindex(ctx context.Context, directory string, numWorker int) error {
+	taskIdxs, err := listChunkFiles(directory)
+	if err != nil {
+		return fmt.Errorf("扫描数据块失败: %v", err)
+	}
+	if len(taskIdxs) == 0 {
+		return fmt.Errorf("目录 %s 中没有数据块文件", directory)
+	}
+
+	indexTable := NewIndexTable()
+	taskChannel := make(chan int, len(taskIdxs))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < numWorker; i++ {
+		wg.Add(1)
+		go worker_reindex(ctx, i, taskChannel, indexTable, directory, &wg)
+	}
+
+	for _, taskIdx := range taskIdxs {
+		taskChannel <- taskIdx
+	}
+	close(taskChannel)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// 保留原索引表，防止误操作覆盖
+	indexTablePath := filepath.Join(directory, "IndexTable.gob")
+	if _, err := os.Stat(indexTablePath); err == nil {
+		if err := os.Rename(indexTablePath, indexTablePath+".bak"); err != nil {
+			return fmt.Errorf("备份原索引表失败: %v", err)
+		}
+		log.Printf("原索引表已备份为 %s.bak", indexTablePath)
+	}
+
+	if err := indexTable.SerializeIndexTable(directory); err != nil {
+		return fmt.Errorf("序列化IndexTable失败: %v", err)
+	}
+	log.Printf("索引重建完成，共登记 %d/%d 个数据块", len(indexTable.Chunks), len(taskIdxs))
+	return nil
+}
@@ -0,0 +1,87 @@
+package trackstore
+
+import (
+	"fmt"
+	"log"
+)
+
+// worker_1 的清洗结果
+type cleanResult struct {
+	TaskIdx    int
+	Trajectory string
+	Seq        int
+	Points     []Point
+	PointsIn   int // 数据块本身（不含重叠部分）的点数
+	Fixed      int // 被修正的异常点数
+}
+
+// worker_2 对单个数据块的处理结果
+type chunkResult struct {
+	TaskIdx   int
+	PointsIn  int
+	PointsOut int
+	Fixed     int
+	Skipped   bool
+	Err       error
+}
+
+// 单个数据块的失败原因
+type chunkError struct {
+	TaskIdx int
+	Stage   string
+	Err     error
+}
+
+func (e *chunkError) Error() string {
+	return fmt.Sprintf("数据块 %d %s失败: %v", e.TaskIdx, e.Stage, e.Err)
+}
+
+func (e *chunkError) Unwrap() error {
+	return e.Err
+}
+
+// Report 一次写入的运行报告
+type Report struct {
+	Chunks     int // 划分出的数据块总数
+	Succeeded  int
+	Failed     int
+	Skipped    int // 清洗后为空或因中断未处理的数据块
+	PointsIn   int
+	PointsOut  int
+	Fixed      int
+	Validation Validation // 划分前校验各规则命中的点数
+	Errors     []error
+}
+
+func (r *Report) add(result chunkResult) {
+	r.PointsIn += result.PointsIn
+	r.PointsOut += result.PointsOut
+	r.Fixed += result.Fixed
+	switch {
+	case result.Err != nil:
+		r.Failed++
+		r.Errors = append(r.Errors, result.Err)
+	case result.Skipped:
+		r.Skipped++
+	default:
+		r.Succeeded++
+	}
+}
+
+// Print 将报告输出到日志
+func (r *Report) Print() {
+	r.Validation.Print()
+	log.Printf("数据块: 共 %d 个，成功 %d，失败 %d，跳过 %d", r.Chunks, r.Succeeded, r.Failed, r.Skipped)
+	log.Printf("轨迹点: 输入 %d，输出 %d，修正异常点 %d", r.PointsIn, r.PointsOut, r.Fixed)
+	for _, err := range r.Errors {
+		log.Printf("错误: %v", err)
+	}
+}
+
+// Err 在有数据块失败时返回错误
+func (r *Report) Err() error {
+	if r.Failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d 个数据块处理失败", r.Failed)
+}
@@ -0,0 +1,347 @@
+package trackstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrNotFound 没有包含查询点的数据块
+var ErrNotFound = errors.New("没有包含该点的数据块")
+
+// Options 数据块划分与并发参数
+type Options struct {
+	MaxLon       float64 // 单个数据块的经度跨度阈值
+	MaxLat       float64 // 单个数据块的纬度跨度阈值
+	Overlap      int     // 相邻数据块之间重叠的点数
+	CleanWorkers int
+	WriteWorkers int
+	AutoTune     bool            // 根据队列积压自动扩容
+	QueryWorkers int             // 查询时并发读取数据块的 goroutine 数量
+	CacheChunks  int             // 缓存已解码数据块的个数，0 表示不缓存
+	Distance     Metric          // 清洗与查询使用的距离度量，为 nil 时使用 Haversine；清洗时两点都有海拔则计入高差
+	MaxClimbRate float64         // 大于 0 时检测升降速度（米/秒）超过该值的高程尖刺
+	WeightHDOP   bool            // 清洗时 HDOP 越大的点越容易被判为异常
+	Clean        CleanMode       // 修正异常点的方式，默认在前后正常点之间插值
+	Kalman       KalmanOptions   // Clean 为 CleanKalman 时按定位精度拒绝与加权的参数
+	InputCRS     CRS             // 写入与清洗前将点从该坐标系转换为 WGS84，为 nil 时视为 WGS84
+	Validate     ValidateOptions // 写入与清洗前的坐标校验，零值表示不校验
+}
+
+func DefaultOptions() Options {
+	return Options{
+		MaxLon:       0.001,
+		MaxLat:       0.001,
+		Overlap:      2,
+		CleanWorkers: DefaultWorkers(),
+		WriteWorkers: DefaultWorkers(),
+		QueryWorkers: DefaultWorkers(),
+		Validate:     DefaultValidateOptions(),
+	}
+}
+
+// Check 检查数据块划分参数：重叠点数不能为负数，经纬度跨度阈值必须大于 0
+func (o Options) Check() error {
+	if o.Overlap < 0 {
+		return fmt.Errorf("重叠点数不能为负数: %d", o.Overlap)
+	}
+	if !(o.MaxLon > 0) || !(o.MaxLat > 0) {
+		return fmt.Errorf("经纬度跨度阈值必须大于 0: %v, %v", o.MaxLon, o.MaxLat)
+	}
+	return nil
+}
+
+// Store 一个存储目录，包含 IndexTable.gob 与若干 <n>.gob 数据块文件
+type Store struct {
+	dir  string
+	opts Options
+
+	mu    sync.Mutex // 串行化 Append 与 Close
+	index *IndexTable
+	cache *chunkCache // 为 nil 时不缓存
+	next  int         // 下一个数据块的任务号
+	dirty bool        // 索引表尚未保存
+}
+
+// Open 打开存储目录，目录或索引表不存在时创建空存储
+func Open(directory string, opts Options) (*Store, error) {
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("创建目录失败: %v", err)
+	}
+
+	s := &Store{dir: directory, opts: opts}
+	if opts.CacheChunks > 0 {
+		s.cache = newChunkCache(opts.CacheChunks)
+	}
+	indexTablePath := filepath.Join(directory, "IndexTable.gob")
+	if _, err := os.Stat(indexTablePath); os.IsNotExist(err) {
+		s.index = NewIndexTable()
+		s.dirty = true
+		return s, nil
+	}
+
+	indexTable, err := ReadIndexTable(directory)
+	if err != nil {
+		return nil, err
+	}
+	s.index = indexTable
+	for _, taskIdx := range indexTable.Ranges {
+		s.next = max(s.next, taskIdx+1)
+	}
+	for taskIdx := range indexTable.Chunks {
+		s.next = max(s.next, taskIdx+1)
+	}
+	return s, nil
+}
+
+// Dir 存储目录路径
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Index 存储的索引表
+func (s *Store) Index() *IndexTable {
+	return s.index
+}
+
+// Append 校验、划分、清洗并写入一条名为 trajectory 的轨迹，新数据块的任务号接在已有数据块之后，
+// 同名轨迹已有数据块时序号接在其后。
+// 命中 reject 规则时不写入任何数据块，返回 *ValidationError；划分参数无效时返回 Options.Check 的错误。
+// ctx 取消后不再派发新的数据块，已清洗完的数据块仍会写入，最后保存索引表。
+func (s *Store) Append(ctx context.Context, trajectory string, points []Point) (Report, error) {
+	if err := s.opts.Check(); err != nil {
+		return Report{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	points, validation, err := prepare(points, s.opts)
+	if err != nil {
+		return Report{Validation: validation}, err
+	}
+
+	// 划分数据块
+	tasks := Split(points, s.opts.MaxLon, s.opts.MaxLat, s.opts.Overlap)
+	// 追加到已有轨迹时序号接在已有数据块之后
+	firstSeq := s.index.nextSeq(trajectory)
+	for i := range tasks {
+		tasks[i].TaskCode += s.next
+		tasks[i].Trajectory = trajectory
+		tasks[i].Seq = firstSeq + i
+		tasks[i].Metric = s.opts.Distance
+		tasks[i].MaxClimbRate = s.opts.MaxClimbRate
+		tasks[i].WeightHDOP = s.opts.WeightHDOP
+		tasks[i].Clean = s.opts.Clean
+		tasks[i].Kalman = s.opts.Kalman
+	}
+	s.next += len(tasks)
+	s.dirty = true
+
+	indexTable := s.index
+	directory := s.dir
+	opts := s.opts
+
+	taskChannel := make(chan Data, len(tasks))
+	worker1Channel := make(chan cleanResult, len(tasks))
+	worker2Channel := make(chan chunkResult, len(tasks))
+
+	// 清洗为 CPU 密集型，上限为 GOMAXPROCS；写入为 I/O 密集型，允许更多 goroutine
+	numWorker1 := max(opts.CleanWorkers, 1)
+	numWorker2 := max(opts.WriteWorkers, 1)
+	maxWorker1, maxWorker2 := numWorker1, numWorker2
+	if opts.AutoTune {
+		maxWorker1 = DefaultWorkers()
+		maxWorker2 = 4 * DefaultWorkers()
+	}
+
+	// 启动worker_1线程
+	pool1 := newWorkerPool("worker_1", numWorker1, maxWorker1, func(id int) {
+		worker_1(ctx, id, taskChannel, worker1Channel)
+	})
+
+	// 启动worker_2线程
+	pool2 := newWorkerPool("worker_2", numWorker2, maxWorker2, func(id int) {
+		worker_2(id, worker1Channel, worker2Channel, indexTable, directory)
+	})
+
+	// 根据两级队列的积压情况分别扩容
+	// 返回前等待 autoTune 退出，Append 结束后不留下任何 goroutine
+	stopTune := make(chan struct{})
+	var tuning sync.WaitGroup
+	if opts.AutoTune {
+		tuning.Add(1)
+		go func() {
+			defer tuning.Done()
+			autoTune(stopTune, 20*time.Millisecond,
+				queueProbe{pool1, func() int { return len(taskChannel) }},
+				queueProbe{pool2, func() int { return len(worker1Channel) }},
+			)
+		}()
+	}
+
+	// 将任务数据放入taskChannel
+	for _, task := range tasks {
+		if ctx.Err() != nil {
+			break
+		}
+		taskChannel <- task
+	}
+	close(taskChannel)
+
+	// 等待worker_1完成
+	pool1.Wait()
+	close(worker1Channel)
+
+	// 等待worker_2完成
+	pool2.Wait()
+	close(worker2Channel)
+	close(stopTune)
+	tuning.Wait()
+
+	if opts.AutoTune {
+		log.Printf("自动调节结束: worker_1 %d 个，worker_2 %d 个", pool1.Size(), pool2.Size())
+	}
+	// 汇总每个数据块的结果，因中断未处理的数据块计为跳过
+	report := Report{Chunks: len(tasks), Validation: validation}
+	for result := range worker2Channel {
+		report.add(result)
+	}
+	report.Skipped += report.Chunks - report.Succeeded - report.Failed - report.Skipped
+
+	// 各 worker_2 保存的快照先后顺序不定，最后统一保存一次
+	if err := s.flush(); err != nil {
+		return report, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	return report, report.Err()
+}
+
+// Lookup 返回外包矩形包含点 p 的数据块中的全部点
+func (s *Store) Lookup(p Point) ([]Point, error) {
+	chunk, err := s.LookupChunk(p)
+	return chunk.Points, err
+}
+
+// LookupChunk 与 Lookup 相同，同时返回数据块的元信息
+func (s *Store) LookupChunk(p Point) (Chunk, error) {
+	taskIdx, found := s.index.Contains(p.Longitude, p.Latitude)
+	if !found {
+		return Chunk{}, ErrNotFound
+	}
+	meta, _ := s.index.Meta(taskIdx)
+	points, err := s.readChunk(taskIdx)
+	if err != nil {
+		return Chunk{Meta: meta}, err
+	}
+	// 数据块可能来自缓存，返回深拷贝，调用方修改结果（包括附加属性与修改前的点）不影响缓存
+	return Chunk{Meta: meta, Points: clonePoints(points)}, nil
+}
+
+// 深拷贝点列表，Attrs 与 Original 指向新的副本
+func clonePoints(points []Point) []Point {
+	if points == nil {
+		return nil
+	}
+	result := make([]Point, len(points))
+	for i, p := range points {
+		result[i] = clonePoint(p)
+	}
+	return result
+}
+
+func clonePoint(p Point) Point {
+	p.Attrs = p.Attrs.clone()
+	if p.Original != nil {
+		original := clonePoint(*p.Original)
+		p.Original = &original
+	}
+	return p
+}
+
+// 读取数据块，启用缓存时优先从缓存中取。
+// 数据块文件写入后不再修改，任务号也不会复用，缓存无需失效。
+func (s *Store) readChunk(taskIdx int) ([]Point, error) {
+	if s.cache == nil {
+		return ReadChunk(s.dir, taskIdx)
+	}
+	if points, ok := s.cache.get(taskIdx); ok {
+		return points, nil
+	}
+	points, err := ReadChunk(s.dir, taskIdx)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.put(taskIdx, points)
+	return points, nil
+}
+
+// Stats 存储概况
+type Stats struct {
+	Chunks       int
+	Points       int
+	Trajectories int
+	Min          Point // 全部数据块的外包矩形
+	Max          Point
+	StartTime    time.Time // 有定位时间的点的时间范围
+	EndTime      time.Time
+	CacheHits    uint64
+	CacheMisses  uint64
+	CacheChunks  int // 当前缓存的数据块个数
+}
+
+// Stats 根据索引表汇总存储概况，不读取数据块文件
+func (s *Store) Stats() Stats {
+	var st Stats
+	trajectories := make(map[string]bool)
+	for i, meta := range s.index.Metas() {
+		st.Chunks++
+		st.Points += meta.Count
+		trajectories[meta.Trajectory] = true
+		if i == 0 {
+			st.Min, st.Max = meta.Min, meta.Max
+		}
+		st.Min.Longitude = min(st.Min.Longitude, meta.Min.Longitude)
+		st.Min.Latitude = min(st.Min.Latitude, meta.Min.Latitude)
+		st.Max.Longitude = max(st.Max.Longitude, meta.Max.Longitude)
+		st.Max.Latitude = max(st.Max.Latitude, meta.Max.Latitude)
+		if !meta.StartTime.IsZero() && (st.StartTime.IsZero() || meta.StartTime.Before(st.StartTime)) {
+			st.StartTime = meta.StartTime
+		}
+		if meta.EndTime.After(st.EndTime) {
+			st.EndTime = meta.EndTime
+		}
+	}
+	st.Trajectories = len(trajectories)
+	if s.cache != nil {
+		st.CacheHits, st.CacheMisses, st.CacheChunks = s.cache.stats()
+	}
+	return st
+}
+
+// 调用方需持有 s.mu
+func (s *Store) flush() error {
+	if err := s.index.SerializeIndexTable(s.dir); err != nil {
+		return fmt.Errorf("序列化IndexTable失败: %v", err)
+	}
+	s.dirty = false
+	return nil
+}
+
+// Close 保存尚未写入磁盘的索引表
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+	return s.flush()
+}
@@ -0,0 +1,229 @@
+package trackstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// 沿经度方向均匀分布的测试轨迹
+func linePoints(n int, lon0 float64) []Point {
+	points := make([]Point, n)
+	for i := range points {
+		points[i] = Point{Longitude: lon0 + float64(i)*0.0003, Latitude: 30.0}
+	}
+	return points
+}
+
+func TestStoreAppendLookup(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir, DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+
+	points := linePoints(50, 120.0)
+	report, err := store.Append(context.Background(), "a", points)
+	if err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if report.PointsOut != len(points) || report.Failed != 0 {
+		t.Errorf("报告不正确: %+v", report)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("关闭存储失败: %v", err)
+	}
+
+	// 重新打开后追加，任务号应接在已有数据块之后
+	store, err = Open(dir, DefaultOptions())
+	if err != nil {
+		t.Fatalf("重新打开存储失败: %v", err)
+	}
+	chunks := len(store.Index().Chunks)
+	if _, err := store.Append(context.Background(), "b", linePoints(50, 121.0)); err != nil {
+		t.Fatalf("追加失败: %v", err)
+	}
+	if got := len(store.Index().Chunks); got != 2*chunks {
+		t.Errorf("追加后数据块数量不正确，期望 %d，实际 %d", 2*chunks, got)
+	}
+
+	found, err := store.Lookup(points[10])
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(found) == 0 {
+		t.Errorf("查询结果为空")
+	}
+	if _, err := store.Lookup(Point{Longitude: 0, Latitude: 0}); err != ErrNotFound {
+		t.Errorf("查询不存在的点应返回 ErrNotFound，实际 %v", err)
+	}
+}
+
+func TestAppendInvalidOptions(t *testing.T) {
+	for _, change := range []func(*Options){
+		func(o *Options) { o.Overlap = -1 },
+		func(o *Options) { o.MaxLon = 0 },
+		func(o *Options) { o.MaxLat = -0.001 },
+	} {
+		opts := DefaultOptions()
+		change(&opts)
+		store, err := Open(t.TempDir(), opts)
+		if err != nil {
+			t.Fatalf("打开存储失败: %v", err)
+		}
+		if _, err := store.Append(context.Background(), "a", linePoints(50, 120.0)); err == nil {
+			t.Errorf("划分参数无效时 Append 应返回错误: %+v", opts)
+		}
+		if n := len(store.Index().Chunks); n != 0 {
+			t.Errorf("划分参数无效时不应写入数据块，实际 %d 个", n)
+		}
+		if _, _, err := Clean(linePoints(50, 120.0), opts); err == nil {
+			t.Errorf("划分参数无效时 Clean 应返回错误: %+v", opts)
+		}
+		store.Close()
+	}
+}
+
+func TestAppendSameTrajectory(t *testing.T) {
+	store, err := Open(t.TempDir(), DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+
+	first, second := linePoints(30, 120.0), linePoints(30, 120.009)
+	for _, points := range [][]Point{first, second} {
+		if _, err := store.Append(context.Background(), "a", points); err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+	}
+
+	// 第二批的序号接在第一批之后，按序号排列时任务号递增，两批不交错
+	metas := store.Index().Metas()
+	for i, meta := range metas {
+		if meta.Seq != i {
+			t.Errorf("第 %d 个数据块的序号为 %d", i, meta.Seq)
+		}
+		if i > 0 && meta.TaskIdx <= metas[i-1].TaskIdx {
+			t.Errorf("两批数据块交错: %d 在 %d 之后", meta.TaskIdx, metas[i-1].TaskIdx)
+		}
+	}
+	if last := metas[len(metas)-1]; last.Max.Longitude != second[len(second)-1].Longitude {
+		t.Errorf("最后一个数据块应属于第二批: %+v", last)
+	}
+}
+
+func TestReindexRestoresTrajectory(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir, DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	store.Append(context.Background(), "a", linePoints(30, 120.0))
+	store.Append(context.Background(), "b", linePoints(30, 121.0))
+	store.Append(context.Background(), "a", linePoints(30, 120.009))
+	want := store.Index().Metas()
+	store.Close()
+
+	os.Remove(filepath.Join(dir, "IndexTable.gob"))
+	if err := Reindex(context.Background(), dir, 2); err != nil {
+		t.Fatalf("重建索引失败: %v", err)
+	}
+	index, err := ReadIndexTable(dir)
+	if err != nil {
+		t.Fatalf("读取索引表失败: %v", err)
+	}
+	got := index.Metas()
+	if len(got) != len(want) {
+		t.Fatalf("数据块数量期望 %d，实际 %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].TaskIdx != want[i].TaskIdx || got[i].Trajectory != want[i].Trajectory || got[i].Seq != want[i].Seq {
+			t.Errorf("第 %d 个数据块期望 %s/%d（任务 %d），实际 %s/%d（任务 %d）",
+				i, want[i].Trajectory, want[i].Seq, want[i].TaskIdx, got[i].Trajectory, got[i].Seq, got[i].TaskIdx)
+		}
+	}
+}
+
+// 前 n 次调用 Err 返回 nil，之后视为已取消
+type cancelAfter struct {
+	context.Context
+	n atomic.Int64
+}
+
+func (c *cancelAfter) Err() error {
+	if c.n.Add(-1) < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestAppendCancel(t *testing.T) {
+	dir := t.TempDir()
+	opts := DefaultOptions()
+	opts.CleanWorkers = 1
+	store, err := Open(dir, opts)
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+
+	// 派发至多消耗与数据块数相同的次数，余下 2 次留给 worker_1，处理两个数据块后中断
+	points := linePoints(200, 120.0)
+	chunks := len(Split(points, opts.MaxLon, opts.MaxLat, opts.Overlap))
+	ctx := &cancelAfter{Context: context.Background()}
+	ctx.n.Store(int64(chunks + 2))
+	report, err := store.Append(ctx, "a", points)
+	if err != context.Canceled {
+		t.Errorf("中断后应返回 context.Canceled，实际 %v", err)
+	}
+	if report.Chunks != chunks || report.Succeeded == 0 || report.Skipped == 0 || report.Failed != 0 ||
+		report.Succeeded+report.Skipped != report.Chunks {
+		t.Errorf("报告不正确: %+v", report)
+	}
+	store.Close()
+
+	// 索引表已保存，重新打开后每个登记的数据块都可读取
+	store, err = Open(dir, opts)
+	if err != nil {
+		t.Fatalf("重新打开存储失败: %v", err)
+	}
+	defer store.Close()
+	metas := store.Index().Metas()
+	if len(metas) != report.Succeeded {
+		t.Errorf("索引表应登记 %d 个数据块，实际 %d 个", report.Succeeded, len(metas))
+	}
+	for _, meta := range metas {
+		if got, err := ReadChunk(dir, meta.TaskIdx); err != nil || len(got) == 0 {
+			t.Errorf("读取数据块 %d 失败: %v", meta.TaskIdx, err)
+		}
+	}
+}
+
+func TestAppendWriteFailure(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir, DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+
+	// 第一个数据块的文件路径被目录占用，重命名时失败
+	if err := os.Mkdir(filepath.Join(dir, "0.gob"), 0o755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	report, err := store.Append(context.Background(), "a", linePoints(50, 120.0))
+	if err == nil {
+		t.Errorf("写入失败时 Append 应返回错误")
+	}
+	if report.Failed == 0 || report.Err() == nil {
+		t.Errorf("报告应记录写入失败: %+v", report)
+	}
+	if report.Succeeded+report.Failed != report.Chunks {
+		t.Errorf("其余数据块应正常写入: %+v", report)
+	}
+	if _, found := store.Index().Meta(0); found {
+		t.Errorf("写入失败的数据块不应登记到索引表")
+	}
+}
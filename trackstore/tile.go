@@ -0,0 +1,259 @@
+package trackstore
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	TileExtent  = 4096 // 瓦片坐标范围
+	MaxTileZoom = 22
+
+	tileBuffer        = 64  // 瓦片四周多保留的范围，避免线在瓦片边界处断开
+	simplifyTolerance = 2.0 // 简化容差，单位为瓦片坐标，因此随缩放级别自动变化
+)
+
+// ErrInvalidTile 瓦片编号超出范围
+var ErrInvalidTile = errors.New("瓦片编号超出范围")
+
+// 瓦片坐标系中的点，保留定位时间用于要素属性
+type tileVertex struct {
+	x, y float64
+	t    time.Time
+}
+
+// Tile 生成 z/x/y 瓦片的 Mapbox Vector Tile：每条轨迹按数据块顺序连成折线，
+// 裁剪到瓦片范围并按瓦片坐标简化后写入 "tracks" 图层。
+// 每个要素带有 trajectory 属性，有定位时间时带有 start_time、end_time（Unix 秒）。
+// trajectory 不为空时只输出该轨迹。瓦片中没有轨迹时返回空切片。
+func (s *Store) Tile(ctx context.Context, z, x, y int, trajectory string) ([]byte, error) {
+	if z < 0 || z > MaxTileZoom || x < 0 || y < 0 || x >= 1<<z || y >= 1<<z {
+		return nil, ErrInvalidTile
+	}
+	n := float64(int(1) << z)
+	buf := float64(tileBuffer) / TileExtent
+	minLon, maxLat := inverseMercator((float64(x)-buf)/n, (float64(y)-buf)/n)
+	maxLon, minLat := inverseMercator((float64(x)+1+buf)/n, (float64(y)+1+buf)/n)
+	box := BBox{
+		Min: Point{Longitude: minLon, Latitude: minLat},
+		Max: Point{Longitude: maxLon, Latitude: maxLat},
+	}
+
+	// 选出与瓦片相交的数据块，以及它们在轨迹中的前后数据块，使跨数据块穿过瓦片的线段保持连续
+	metas := s.index.Metas()
+	selected := make([]bool, len(metas))
+	for i, meta := range metas {
+		if trajectory != "" && meta.Trajectory != trajectory {
+			continue
+		}
+		if !box.Intersects(meta.Min, meta.Max) {
+			continue
+		}
+		selected[i] = true
+		if i > 0 && meta.Follows(metas[i-1]) {
+			selected[i-1] = true
+		}
+		if i+1 < len(metas) && metas[i+1].Follows(meta) {
+			selected[i+1] = true
+		}
+	}
+
+	project := func(p Point) tileVertex {
+		mx, my := Mercator(p.Longitude, p.Latitude)
+		return tileVertex{(mx*n - float64(x)) * TileExtent, (my*n - float64(y)) * TileExtent, p.Time}
+	}
+
+	layer := &mvtLayer{name: "tracks", extent: TileExtent}
+	var run []tileVertex
+	flush := func(name string) {
+		for _, piece := range clipLine(run, -tileBuffer, TileExtent+tileBuffer) {
+			if f, ok := newTrackFeature(name, piece); ok {
+				layer.features = append(layer.features, f)
+			}
+		}
+		run = run[:0]
+	}
+
+	for i, meta := range metas {
+		if !selected[i] {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(run) > 0 && !(selected[i-1] && meta.Follows(metas[i-1])) {
+			flush(metas[i-1].Trajectory)
+		}
+		points, err := s.readChunk(meta.TaskIdx)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range points {
+			run = append(run, project(p))
+		}
+		if i == len(metas)-1 || !selected[i+1] {
+			flush(meta.Trajectory)
+		}
+	}
+	return encodeTile(layer), nil
+}
+
+// 简化、取整后生成线要素，不足两个点时返回 false
+func newTrackFeature(trajectory string, piece []tileVertex) (mvtFeature, bool) {
+	var start, end time.Time
+	for _, v := range piece {
+		if v.t.IsZero() {
+			continue
+		}
+		if start.IsZero() || v.t.Before(start) {
+			start = v.t
+		}
+		if v.t.After(end) {
+			end = v.t
+		}
+	}
+
+	var line []tilePoint
+	for _, v := range simplifyLine(piece, simplifyTolerance) {
+		p := tilePoint{int(math.Round(v.x)), int(math.Round(v.y))}
+		if len(line) > 0 && line[len(line)-1] == p {
+			continue
+		}
+		line = append(line, p)
+	}
+	if len(line) < 2 {
+		return mvtFeature{}, false
+	}
+
+	f := mvtFeature{
+		lines: [][]tilePoint{line},
+		props: []mvtProperty{{"trajectory", trajectory}},
+	}
+	if !start.IsZero() {
+		f.props = append(f.props, mvtProperty{"start_time", start.Unix()}, mvtProperty{"end_time", end.Unix()})
+	}
+	return f, true
+}
+
+// 将折线裁剪到 [lo, hi] 的正方形内，线段离开范围处断开，返回各段
+func clipLine(line []tileVertex, lo, hi float64) [][]tileVertex {
+	inside := func(v tileVertex) bool {
+		return v.x >= lo && v.x <= hi && v.y >= lo && v.y <= hi
+	}
+
+	var pieces [][]tileVertex
+	var cur []tileVertex
+	for i := 1; i < len(line); i++ {
+		a, b := line[i-1], line[i]
+		ca, cb, ok := clipSegment(a, b, lo, hi)
+		if !ok {
+			if len(cur) > 1 {
+				pieces = append(pieces, cur)
+			}
+			cur = nil
+			continue
+		}
+		if len(cur) == 0 || !inside(a) {
+			if len(cur) > 1 {
+				pieces = append(pieces, cur)
+			}
+			cur = []tileVertex{ca}
+		}
+		cur = append(cur, cb)
+		if !inside(b) {
+			pieces = append(pieces, cur)
+			cur = nil
+		}
+	}
+	if len(cur) > 1 {
+		pieces = append(pieces, cur)
+	}
+	return pieces
+}
+
+// Liang-Barsky 线段裁剪，裁剪出的端点按比例插值定位时间
+func clipSegment(a, b tileVertex, lo, hi float64) (tileVertex, tileVertex, bool) {
+	dx, dy := b.x-a.x, b.y-a.y
+	t0, t1 := 0.0, 1.0
+	for _, edge := range [4][2]float64{
+		{-dx, a.x - lo},
+		{dx, hi - a.x},
+		{-dy, a.y - lo},
+		{dy, hi - a.y},
+	} {
+		p, q := edge[0], edge[1]
+		if p == 0 {
+			if q < 0 {
+				return a, b, false
+			}
+			continue
+		}
+		r := q / p
+		if p < 0 {
+			t0 = math.Max(t0, r)
+		} else {
+			t1 = math.Min(t1, r)
+		}
+		if t0 > t1 {
+			return a, b, false
+		}
+	}
+
+	at := func(f float64) tileVertex {
+		v := tileVertex{a.x + f*dx, a.y + f*dy, a.t}
+		switch {
+		case f == 1:
+			v.t = b.t
+		case !a.t.IsZero() && !b.t.IsZero():
+			v.t = a.t.Add(time.Duration(f * float64(b.t.Sub(a.t))))
+		}
+		return v
+	}
+	return at(t0), at(t1), true
+}
+
+// Douglas-Peucker 折线简化
+func simplifyLine(line []tileVertex, tolerance float64) []tileVertex {
+	if len(line) < 3 {
+		return line
+	}
+	keep := make([]bool, len(line))
+	keep[0], keep[len(line)-1] = true, true
+
+	var simplify func(first, last int)
+	simplify = func(first, last int) {
+		maxDist, index := 0.0, -1
+		for i := first + 1; i < last; i++ {
+			if d := segmentDistance(line[i], line[first], line[last]); d > maxDist {
+				maxDist, index = d, i
+			}
+		}
+		if index >= 0 && maxDist > tolerance {
+			keep[index] = true
+			simplify(first, index)
+			simplify(index, last)
+		}
+	}
+	simplify(0, len(line)-1)
+
+	result := make([]tileVertex, 0, len(line))
+	for i, v := range line {
+		if keep[i] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// 点 p 到线段 ab 的距离
+func segmentDistance(p, a, b tileVertex) float64 {
+	dx, dy := b.x-a.x, b.y-a.y
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p.x-a.x, p.y-a.y)
+	}
+	t := ((p.x-a.x)*dx + (p.y-a.y)*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p.x-(a.x+t*dx), p.y-(a.y+t*dy))
+}
@@ -0,0 +1,196 @@
+package trackstore
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// 解析 protobuf 消息中的字段，varint 字段返回数值，bytes 字段返回内容
+func decodeFields(t *testing.T, b []byte) map[protowire.Number][]any {
+	fields := make(map[protowire.Number][]any)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("解析标签失败: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			fields[num] = append(fields[num], v)
+			b = b[n:]
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			fields[num] = append(fields[num], v)
+			b = b[n:]
+		default:
+			t.Fatalf("字段 %d 的类型 %v 不应出现", num, typ)
+		}
+	}
+	return fields
+}
+
+// 包含点 p 的 z 级瓦片编号
+func tileOf(p Point, z int) (int, int) {
+	x, y := Mercator(p.Longitude, p.Latitude)
+	n := float64(int(1) << z)
+	return int(math.Floor(x * n)), int(math.Floor(y * n))
+}
+
+func TestTile(t *testing.T) {
+	store, err := Open(t.TempDir(), DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+
+	start := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+	points := linePoints(100, 120.0)
+	for i := range points {
+		points[i].Time = start.Add(time.Duration(i) * time.Second)
+	}
+	if _, err := store.Append(context.Background(), "a", points); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	x, y := tileOf(points[50], 14)
+	tile, err := store.Tile(context.Background(), 14, x, y, "")
+	if err != nil {
+		t.Fatalf("生成瓦片失败: %v", err)
+	}
+
+	layers := decodeFields(t, tile)[mvtTileLayers]
+	if len(layers) != 1 {
+		t.Fatalf("期望 1 个图层，实际 %d 个", len(layers))
+	}
+	layer := decodeFields(t, layers[0].([]byte))
+	if name := string(layer[mvtLayerName][0].([]byte)); name != "tracks" {
+		t.Errorf("图层名称不正确: %s", name)
+	}
+	if len(layer[mvtLayerFeatures]) == 0 {
+		t.Fatalf("瓦片中没有要素")
+	}
+	var keys []string
+	for _, k := range layer[mvtLayerKeys] {
+		keys = append(keys, string(k.([]byte)))
+	}
+	if len(keys) != 3 || keys[0] != "trajectory" {
+		t.Errorf("属性名不正确: %v", keys)
+	}
+
+	// 直线轨迹简化后应只剩一条线的两个端点
+	feature := decodeFields(t, layer[mvtLayerFeatures][0].([]byte))
+	if feature[mvtFeatureType][0].(uint64) != mvtLineString {
+		t.Errorf("要素类型应为线")
+	}
+	geometry := feature[mvtFeatureGeometry][0].([]byte)
+	var cmds []uint64
+	for len(geometry) > 0 {
+		v, n := protowire.ConsumeVarint(geometry)
+		cmds = append(cmds, v)
+		geometry = geometry[n:]
+	}
+	if len(cmds) != 6 || cmds[0] != mvtCmdMoveTo|1<<3 || cmds[3] != mvtCmdLineTo|1<<3 {
+		t.Errorf("几何命令不正确: %v", cmds)
+	}
+
+	// 远离轨迹的瓦片为空，其他轨迹的过滤同理
+	if tile, _ := store.Tile(context.Background(), 14, 0, 0, ""); len(tile) != 0 {
+		t.Errorf("远离轨迹的瓦片应为空")
+	}
+	if tile, _ := store.Tile(context.Background(), 14, x, y, "b"); len(tile) != 0 {
+		t.Errorf("只显示轨迹 b 时瓦片应为空")
+	}
+	if _, err := store.Tile(context.Background(), 2, 4, 0, ""); err != ErrInvalidTile {
+		t.Errorf("超出范围的瓦片应返回 ErrInvalidTile，实际 %v", err)
+	}
+}
+
+func TestClipLine(t *testing.T) {
+	// 穿出再穿入范围的折线应被分成两段，裁剪点位于边界上
+	line := []tileVertex{{x: 10, y: 10}, {x: 200, y: 10}, {x: 200, y: 50}, {x: 10, y: 50}}
+	pieces := clipLine(line, 0, 100)
+	if len(pieces) != 2 {
+		t.Fatalf("期望 2 段，实际 %d 段", len(pieces))
+	}
+	if end := pieces[0][len(pieces[0])-1]; end.x != 100 || end.y != 10 {
+		t.Errorf("第一段终点不正确: %+v", end)
+	}
+	if begin := pieces[1][0]; begin.x != 100 || begin.y != 50 {
+		t.Errorf("第二段起点不正确: %+v", begin)
+	}
+}
+
+// 轨迹穿过瓦片：前后相邻的数据块都在瓦片外时，线仍应延伸到瓦片两侧的边界
+func TestTileCrossing(t *testing.T) {
+	store, err := Open(t.TempDir(), DefaultOptions())
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+
+	// 点间距大于 MaxLon，每个数据块只有一个点；z=14 的瓦片约 0.022 度宽
+	points := make([]Point, 10)
+	for i := range points {
+		points[i] = Point{Longitude: 120.0 + float64(i)*0.01, Latitude: 30.0}
+	}
+	if _, err := store.Append(context.Background(), "a", points); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+
+	x, y := tileOf(points[5], 14)
+	tile, err := store.Tile(context.Background(), 14, x, y, "")
+	if err != nil {
+		t.Fatalf("生成瓦片失败: %v", err)
+	}
+	layers := decodeFields(t, tile)[mvtTileLayers]
+	if len(layers) != 1 {
+		t.Fatalf("期望 1 个图层，实际 %d 个", len(layers))
+	}
+	features := decodeFields(t, layers[0].([]byte))[mvtLayerFeatures]
+	if len(features) != 1 {
+		t.Fatalf("期望 1 条线，实际 %d 条", len(features))
+	}
+
+	// 依次累加各顶点的 x 增量，得到线的横向范围
+	geometry := decodeFields(t, features[0].([]byte))[mvtFeatureGeometry][0].([]byte)
+	var cmds []uint64
+	for len(geometry) > 0 {
+		v, n := protowire.ConsumeVarint(geometry)
+		cmds = append(cmds, v)
+		geometry = geometry[n:]
+	}
+	if len(cmds) < 6 {
+		t.Fatalf("几何命令不正确: %v", cmds)
+	}
+	vx := protowire.DecodeZigZag(cmds[1])
+	minX, maxX := vx, vx
+	for i := 4; i+1 < len(cmds); i += 2 {
+		vx += protowire.DecodeZigZag(cmds[i])
+		minX, maxX = min(minX, vx), max(maxX, vx)
+	}
+	if minX > 0 || maxX < TileExtent {
+		t.Errorf("线应穿过整个瓦片，实际 x 范围 [%d, %d]", minX, maxX)
+	}
+}
+
+func TestChunkMetaFollows(t *testing.T) {
+	prev := ChunkMeta{Trajectory: "a", Seq: 3}
+	for _, c := range []struct {
+		meta ChunkMeta
+		want bool
+	}{
+		{ChunkMeta{Trajectory: "a", Seq: 4}, true},
+		{ChunkMeta{Trajectory: "a", Seq: 5}, false},
+		{ChunkMeta{Trajectory: "a", Seq: 2}, false},
+		{ChunkMeta{Trajectory: "b", Seq: 4}, false},
+	} {
+		if got := c.meta.Follows(prev); got != c.want {
+			t.Errorf("%+v.Follows(%+v) 期望 %v，实际 %v", c.meta, prev, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package trackstore
+
+import (
+	"log"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// DefaultWorkers 默认的 goroutine 数量
+func DefaultWorkers() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// 可在运行中扩容的 goroutine 池。
+// 只有仍有 goroutine 在运行时才允许扩容，保证 wg.Add 不会发生在 Wait 返回之后。
+type workerPool struct {
+	name string
+	max  int
+	run  func(id int)
+
+	mu     sync.Mutex
+	size   int // 启动过的 goroutine 总数
+	active int // 仍在运行的 goroutine 数
+	wg     sync.WaitGroup
+}
+
+func newWorkerPool(name string, size, limit int, run func(id int)) *workerPool {
+	p := &workerPool{name: name, max: max(size, limit), run: run}
+	p.mu.Lock()
+	for i := 0; i < size; i++ {
+		p.start()
+	}
+	p.mu.Unlock()
+	return p
+}
+
+// 调用方需持有 p.mu
+func (p *workerPool) start() {
+	id := p.size
+	p.size++
+	p.active++
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.run(id)
+		p.mu.Lock()
+		p.active--
+		p.mu.Unlock()
+	}()
+}
+
+// 增加一个 goroutine，已达上限或池已结束时返回 false
+func (p *workerPool) grow() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active == 0 || p.size >= p.max {
+		return false
+	}
+	p.start()
+	return true
+}
+
+func (p *workerPool) Wait() {
+	p.wg.Wait()
+}
+
+func (p *workerPool) Size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.size
+}
+
+// 被监测的队列及其消费者池
+type queueProbe struct {
+	pool  *workerPool
+	depth func() int
+}
+
+// 定期检查各队列的积压深度，积压超过消费者数量时为对应的池扩容，直到 stop 关闭
+func autoTune(stop <-chan struct{}, interval time.Duration, probes ...queueProbe) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		for _, probe := range probes {
+			depth := probe.depth()
+			if depth > probe.pool.Size() && probe.pool.grow() {
+				log.Printf("自动调节: 队列积压 %d，%s 扩容到 %d 个 goroutine", depth, probe.pool.name, probe.pool.Size())
+			}
+		}
+	}
+}
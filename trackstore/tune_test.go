@@ -0,0 +1,104 @@
+package trackstore
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolGrow(t *testing.T) {
+	release := make(chan struct{})
+	pool := newWorkerPool("test", 1, 3, func(int) { <-release })
+
+	if !pool.grow() || !pool.grow() {
+		t.Fatalf("未达上限时应能扩容")
+	}
+	if pool.grow() || pool.Size() != 3 {
+		t.Errorf("达到上限 3 后不应再扩容，实际 %d 个", pool.Size())
+	}
+
+	close(release)
+	pool.Wait()
+	if pool.grow() || pool.Size() != 3 {
+		t.Errorf("池结束后不应再扩容，实际 %d 个", pool.Size())
+	}
+
+	// 上限小于初始数量时按初始数量计
+	done := make(chan struct{})
+	small := newWorkerPool("test", 2, 1, func(int) { <-done })
+	if small.grow() || small.Size() != 2 {
+		t.Errorf("初始数量已超过上限时不应扩容，实际 %d 个", small.Size())
+	}
+	close(done)
+	small.Wait()
+}
+
+func TestAutoTune(t *testing.T) {
+	release := make(chan struct{})
+	pool := newWorkerPool("test", 1, 4, func(int) { <-release })
+
+	stop := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		autoTune(stop, time.Millisecond, queueProbe{pool, func() int { return 100 }})
+		close(finished)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for pool.Size() < 4 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if pool.Size() != 4 {
+		t.Errorf("积压时应扩容到上限 4，实际 %d 个", pool.Size())
+	}
+	time.Sleep(5 * time.Millisecond)
+	if pool.Size() != 4 {
+		t.Errorf("达到上限后不应继续扩容，实际 %d 个", pool.Size())
+	}
+
+	close(stop)
+	<-finished
+	close(release)
+	pool.Wait()
+}
+
+// 统计调用栈中包含 name 的 goroutine 数
+func goroutinesIn(name string) int {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+	n := 0
+	for _, g := range strings.Split(string(buf), "\n\n") {
+		if strings.Contains(g, name) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestAppendAutoTuneGoroutines(t *testing.T) {
+	opts := DefaultOptions()
+	opts.AutoTune = true
+	opts.CleanWorkers, opts.WriteWorkers = 1, 1
+	store, err := Open(t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+
+	before := runtime.NumGoroutine()
+	if _, err := store.Append(context.Background(), "a", linePoints(2000, 120.0)); err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	if n := goroutinesIn("trackstore.autoTune"); n != 0 {
+		t.Errorf("Append 返回后 autoTune 应已退出，实际还有 %d 个", n)
+	}
+	// worker 调用 wg.Done 后还需片刻才真正退出
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("Append 返回后 goroutine 数应回到 %d，实际 %d", before, n)
+	}
+}
@@ -0,0 +1,187 @@
+package trackstore
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"path/filepath"
+	"time"
+)
+
+// Point 轨迹点的经纬度、海拔与定位时间，没有时间信息时 Time 为零值
+type Point struct {
+	Longitude float64
+	Latitude float64
+	Altitude float64 // 海拔（米），HasAltitude 为 false 时无意义
+	HasAltitude bool
+	Time time.Time
+	Flag PointFlag // 点的来源
+	Original *Point // 清洗修改了位置时为修改前的点，否则为 nil
+	Attrs *Attributes // 设备记录的速度、航向、HDOP 等附加属性，没有时为 nil
+}
+
+// PointFlag 轨迹点的来源：原始记录，或由清洗插值、平滑得到
+type PointFlag uint8
+
+const (
+	FlagOriginal PointFlag = iota
+	FlagInterpolated
+	FlagSmoothed
+)
+
+func (f PointFlag) String() string {
+	switch f {
+	case FlagOriginal:
+		return "original"
+	case FlagInterpolated:
+		return "interpolated"
+	case FlagSmoothed:
+		return "smoothed"
+	}
+	return fmt.Sprintf("PointFlag(%d)", uint8(f))
+}
+
+// Data 一个待清洗的数据块：Points[Start:End+1] 为数据块本身，其余为与相邻数据块重叠的点
+type Data struct {
+	Points []Point
+	Start int
+	End int
+	TaskCode int
+	Trajectory string // 所属轨迹
+	Seq int           // 在所属轨迹中的序号
+	Metric Metric     // 检测速度突变使用的距离度量，为 nil 时使用 Haversine
+	MaxClimbRate float64 // 大于 0 时，升降速度（米/秒）超过该值后立即反向的点视为异常
+	WeightHDOP bool      // 按 FixWeight 收紧 HDOP 较大的点的异常阈值，水平误差按 Kalman.UERE 换算
+	Clean CleanMode      // 修正异常点的方式
+	Kalman KalmanOptions // Clean 为 CleanKalman 时的参数
+}
+
+// ChunkMeta 数据块元信息：外包矩形、点数、所属轨迹与时间范围
+type ChunkMeta struct {
+	TaskIdx    int
+	Count      int
+	Min        Point
+	Max        Point
+	Trajectory string
+	Seq        int
+	StartTime  time.Time
+	EndTime    time.Time
+}
+
+// Follows m 是否为同一轨迹中紧接在 prev 之后的数据块
+func (m ChunkMeta) Follows(prev ChunkMeta) bool {
+	return m.Trajectory == prev.Trajectory && m.Seq == prev.Seq+1
+}
+
+// IndexTable 数据块索引：外包矩形到任务号的映射及各数据块的元信息
+type IndexTable struct {
+	Ranges map[string]int
+	Chunks map[int]ChunkMeta
+	mu     sync.RWMutex
+}
+
+func NewIndexTable() *IndexTable {
+	return &IndexTable{
+		Ranges: make(map[string]int),
+		Chunks: make(map[int]ChunkMeta),
+	}
+}
+
+// 计算数据块的外包矩形（经纬度最值）与时间范围，没有时间信息的点不参与时间范围
+func newChunkMeta(taskIdx int, points []Point) ChunkMeta {
+	meta := ChunkMeta{TaskIdx: taskIdx, Count: len(points)}
+	if len(points) == 0 {
+		return meta
+	}
+	meta.Min = Point{Longitude: points[0].Longitude, Latitude: points[0].Latitude}
+	meta.Max = meta.Min
+	for _, p := range points {
+		meta.Min.Longitude = math.Min(meta.Min.Longitude, p.Longitude)
+		meta.Min.Latitude = math.Min(meta.Min.Latitude, p.Latitude)
+		meta.Max.Longitude = math.Max(meta.Max.Longitude, p.Longitude)
+		meta.Max.Latitude = math.Max(meta.Max.Latitude, p.Latitude)
+		if p.Time.IsZero() {
+			continue
+		}
+		if meta.StartTime.IsZero() || p.Time.Before(meta.StartTime) {
+			meta.StartTime = p.Time
+		}
+		if p.Time.After(meta.EndTime) {
+			meta.EndTime = p.Time
+		}
+	}
+	return meta
+}
+
+// AddChunk 登记数据块：外包矩形写入 Ranges，元信息写入 Chunks
+func (it *IndexTable) AddChunk(meta ChunkMeta) {
+	it.AddRange(meta.Min, meta.Max, meta.TaskIdx)
+
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	// 旧版本的索引表解码后没有 Chunks
+	if it.Chunks == nil {
+		it.Chunks = make(map[int]ChunkMeta)
+	}
+	it.Chunks[meta.TaskIdx] = meta
+}
+
+// 轨迹 trajectory 下一个数据块的序号：已有数据块的最大序号加 1，没有数据块时为 0
+func (it *IndexTable) nextSeq(trajectory string) int {
+	it.mu.RLock()
+	defer it.mu.RUnlock()
+	next := 0
+	for _, meta := range it.Chunks {
+		if meta.Trajectory == trajectory {
+			next = max(next, meta.Seq+1)
+		}
+	}
+	return next
+}
+
+// AddRange 登记以 p1 为左下角、p2 为右上角的范围
+func (it *IndexTable) AddRange(p1, p2 Point, taskIdx int) {
+    it.mu.Lock()  // 写优先
+    defer it.mu.Unlock()
+    // 生成索引 key
+    rangeKey := fmt.Sprintf("%f,%f,%f,%f", p1.Longitude, p1.Latitude, p2.Longitude, p2.Latitude)
+    // 索引对应taskIdx
+    it.Ranges[rangeKey] = taskIdx
+}
+
+// Contains 查找表格是否具有包含点(x,y)的范围数据块，返回该数据块对应的TaskIdx
+func (it *IndexTable) Contains(x, y float64) (int, bool) {
+	 it.mu.RLock()
+    defer it.mu.RUnlock()
+
+    for key, taskIdx := range it.Ranges {
+        // 解析索引键
+        var p1, p2 Point
+        _, err := fmt.Sscanf(key, "%f,%f,%f,%f", &p1.Longitude, &p1.Latitude, &p2.Longitude, &p2.Latitude)
+        if err != nil {
+            log.Println("解析索引键时出错:", err)
+            continue
+        }
+
+        // 检查点是否在矩形范围内
+        if x >= p1.Longitude && x <= p2.Longitude && y >= p1.Latitude && y <= p2.Latitude {
+            return taskIdx, true
+        }
+    }
+    return -2, false
+}
+
+// SerializeIndexTable 将表格序列化保存到目录中的 IndexTable.gob
+func (it *IndexTable) SerializeIndexTable(directory string) error {
+	it.mu.RLock()
+	defer it.mu.RUnlock()
+
+	filePath := filepath.Join(directory, "IndexTable.gob")
+	if err := encodeFile(filePath, kindIndex, it); err != nil {
+		return fmt.Errorf("保存索引表失败: %v", err)
+	}
+
+	return nil
+}
+
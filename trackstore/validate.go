@@ -0,0 +1,202 @@
+package trackstore
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+// Action 校验规则命中时的处理方式
+type Action uint8
+
+const (
+	ActionKeep   Action = iota // 保留，只计数
+	ActionDrop                 // 丢弃该点
+	ActionReject               // 拒绝整条轨迹
+	ActionFix                  // 自动修正，只用于经纬度互换
+)
+
+var actionNames = []string{"keep", "drop", "reject", "fix"}
+
+func (a Action) String() string {
+	if int(a) < len(actionNames) {
+		return actionNames[a]
+	}
+	return fmt.Sprintf("Action(%d)", uint8(a))
+}
+
+// MarshalText 与 UnmarshalText 使 Action 可直接用作命令行参数与 JSON 配置
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Action) UnmarshalText(text []byte) error {
+	for i, name := range actionNames {
+		if string(text) == name {
+			*a = Action(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("未知的处理方式: %s，可选 keep、drop、reject 或 fix", text)
+}
+
+// ValidateOptions 各校验规则的处理方式，零值表示全部保留，即不做校验
+type ValidateOptions struct {
+	Invalid    Action // 坐标或海拔为 NaN、无穷大，或超出经纬度范围
+	Swapped    Action // 经纬度两列互换，fix 时交换回来
+	NullIsland Action // 坐标恰为 (0, 0)，通常是定位失败的占位值
+	Duplicate  Action // 与前一个保留的点坐标、时间都相同
+}
+
+// DefaultValidateOptions 交换互换的列，丢弃其余有问题的点
+func DefaultValidateOptions() ValidateOptions {
+	return ValidateOptions{
+		Invalid:    ActionDrop,
+		Swapped:    ActionFix,
+		NullIsland: ActionDrop,
+		Duplicate:  ActionDrop,
+	}
+}
+
+// Check 检查处理方式是否有效
+func (o ValidateOptions) Check() error {
+	for _, rule := range []struct {
+		name   string
+		action Action
+	}{{"invalid", o.Invalid}, {"null-island", o.NullIsland}, {"duplicate", o.Duplicate}} {
+		if rule.action > ActionReject {
+			return fmt.Errorf("规则 %s 不支持 %s", rule.name, rule.action)
+		}
+	}
+	if o.Swapped > ActionFix {
+		return fmt.Errorf("规则 swapped 不支持 %s", o.Swapped)
+	}
+	return nil
+}
+
+// Validation 各规则命中的点数
+type Validation struct {
+	Invalid    int `json:"invalid"`
+	Swapped    int `json:"swapped"`
+	NullIsland int `json:"null_island"`
+	Duplicate  int `json:"duplicate"`
+	Dropped    int `json:"dropped"` // 被丢弃的点数
+}
+
+// Print 将校验结果输出到日志
+func (v Validation) Print() {
+	log.Printf("校验: 无效坐标 %d，经纬度互换 %d，零点 %d，重复 %d，丢弃 %d", v.Invalid, v.Swapped, v.NullIsland, v.Duplicate, v.Dropped)
+}
+
+// ValidationError 命中 reject 规则时返回
+type ValidationError struct {
+	Rule  string
+	Index int // 在输入中的下标
+	Point Point
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("第 %d 个点 (%v, %v) 未通过校验: %s", e.Index+1, e.Point.Longitude, e.Point.Latitude, e.Rule)
+}
+
+func finite(p Point) bool {
+	if p.HasAltitude && (math.IsNaN(p.Altitude) || math.IsInf(p.Altitude, 0)) {
+		return false
+	}
+	return !math.IsNaN(p.Longitude) && !math.IsNaN(p.Latitude) && !math.IsInf(p.Longitude, 0) && !math.IsInf(p.Latitude, 0)
+}
+
+func inRange(p Point) bool {
+	return math.Abs(p.Longitude) <= 180 && math.Abs(p.Latitude) <= 90
+}
+
+// 纬度超出范围而经度可作为纬度的点过半时，认为两列互换
+func swappedColumns(points []Point) bool {
+	swapped, total := 0, 0
+	for _, p := range points {
+		if !finite(p) || (p.Longitude == 0 && p.Latitude == 0) {
+			continue
+		}
+		total++
+		if math.Abs(p.Latitude) > 90 && math.Abs(p.Longitude) <= 90 {
+			swapped++
+		}
+	}
+	return swapped*2 > total
+}
+
+// Validate 按规则检查一条轨迹，返回保留的点与各规则的命中数，不修改 points。
+// 经纬度互换按整列判断，命中时计入全部有效点；命中 reject 规则时返回 *ValidationError
+func Validate(points []Point, opts ValidateOptions) ([]Point, Validation, error) {
+	var v Validation
+	if err := opts.Check(); err != nil {
+		return nil, v, err
+	}
+	if opts == (ValidateOptions{}) {
+		return points, v, nil
+	}
+
+	swapped := swappedColumns(points)
+	kept := make([]Point, 0, len(points))
+	for i, p := range points {
+		// 依次检查各规则，返回 false 表示丢弃该点
+		apply := func(rule string, action Action, count *int) (bool, error) {
+			*count++
+			switch action {
+			case ActionDrop:
+				v.Dropped++
+				return false, nil
+			case ActionReject:
+				return false, &ValidationError{Rule: rule, Index: i, Point: p}
+			}
+			return true, nil
+		}
+
+		ok := true
+		var err error
+		if swapped && finite(p) {
+			if ok, err = apply("swapped", opts.Swapped, &v.Swapped); err != nil {
+				return nil, v, err
+			}
+			if opts.Swapped == ActionFix {
+				p.Longitude, p.Latitude = p.Latitude, p.Longitude
+			}
+		}
+		if ok && (!finite(p) || !inRange(p)) {
+			if ok, err = apply("invalid", opts.Invalid, &v.Invalid); err != nil {
+				return nil, v, err
+			}
+		}
+		if ok && p.Longitude == 0 && p.Latitude == 0 {
+			if ok, err = apply("null-island", opts.NullIsland, &v.NullIsland); err != nil {
+				return nil, v, err
+			}
+		}
+		if ok && len(kept) > 0 {
+			last := kept[len(kept)-1]
+			if last.Longitude == p.Longitude && last.Latitude == p.Latitude && last.Time.Equal(p.Time) {
+				if ok, err = apply("duplicate", opts.Duplicate, &v.Duplicate); err != nil {
+					return nil, v, err
+				}
+			}
+		}
+		if ok {
+			kept = append(kept, p)
+		}
+	}
+	return kept, v, nil
+}
+
+// 校验并转换为 WGS84。地理坐标系在转换前校验，GCJ-02 等偏移需在交换经纬度之后计算；
+// 投影坐标系的单位为米，转换后再校验
+func prepare(points []Point, opts Options) ([]Point, Validation, error) {
+	crs := opts.InputCRS
+	if crs != nil && !crs.Geographic() {
+		return Validate(ToWGS84Points(points, crs), opts.Validate)
+	}
+	points, v, err := Validate(points, opts.Validate)
+	if err != nil {
+		return nil, v, err
+	}
+	return ToWGS84Points(points, crs), v, nil
+}
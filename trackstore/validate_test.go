@@ -0,0 +1,114 @@
+package trackstore
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	points := linePoints(10, 120.0)
+	points[2] = Point{Longitude: math.NaN(), Latitude: 30}
+	points[4] = Point{}
+	points[6] = points[5]
+	points[8].Latitude = 95
+
+	kept, v, err := Validate(points, DefaultValidateOptions())
+	if err != nil {
+		t.Fatalf("校验失败: %v", err)
+	}
+	want := Validation{Invalid: 2, NullIsland: 1, Duplicate: 1, Dropped: 4}
+	if v != want {
+		t.Errorf("命中数期望 %+v，实际 %+v", want, v)
+	}
+	if len(kept) != 6 {
+		t.Errorf("期望保留 6 个点，实际 %d 个", len(kept))
+	}
+
+	// 零值不做任何处理
+	if kept, v, _ := Validate(points, ValidateOptions{}); len(kept) != len(points) || v != (Validation{}) {
+		t.Errorf("零值选项不应校验: %d %+v", len(kept), v)
+	}
+
+	// 只计数不丢弃
+	keep := ValidateOptions{Duplicate: ActionKeep, NullIsland: ActionKeep, Invalid: ActionDrop}
+	if kept, v, _ := Validate(points, keep); len(kept) != 8 || v.NullIsland != 1 || v.Dropped != 2 {
+		t.Errorf("keep 规则不应丢弃点: %d %+v", len(kept), v)
+	}
+
+	opts := DefaultValidateOptions()
+	opts.NullIsland = ActionReject
+	_, _, err = Validate(points, opts)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Rule != "null-island" || verr.Index != 4 {
+		t.Errorf("应在第 5 个点拒绝: %v", err)
+	}
+
+	opts = DefaultValidateOptions()
+	opts.Duplicate = ActionFix
+	if _, _, err := Validate(points, opts); err == nil {
+		t.Errorf("fix 只适用于经纬度互换")
+	}
+}
+
+func TestValidateSwapped(t *testing.T) {
+	points := linePoints(10, 120.0)
+	swapped := make([]Point, len(points))
+	for i, p := range points {
+		swapped[i] = Point{Longitude: p.Latitude, Latitude: p.Longitude}
+	}
+
+	kept, v, err := Validate(swapped, DefaultValidateOptions())
+	if err != nil || v.Swapped != len(points) || len(kept) != len(points) {
+		t.Fatalf("互换的列应被交换回来: %d %+v %v", len(kept), v, err)
+	}
+	for i := range kept {
+		if kept[i] != points[i] {
+			t.Errorf("第 %d 个点期望 %+v，实际 %+v", i, points[i], kept[i])
+		}
+	}
+	if swapped[0].Longitude != 30 {
+		t.Errorf("Validate 不应修改输入的点")
+	}
+
+	// 不修正时纬度越界，按无效坐标处理
+	opts := DefaultValidateOptions()
+	opts.Swapped = ActionKeep
+	if kept, v, _ := Validate(swapped, opts); len(kept) != 0 || v.Swapped != len(points) || v.Invalid != len(points) {
+		t.Errorf("未修正的互换点应判为无效: %d %+v", len(kept), v)
+	}
+
+	// 少数越界的点不足以判断为整列互换
+	points[3].Latitude = 120
+	if _, v, _ := Validate(points, DefaultValidateOptions()); v.Swapped != 0 || v.Invalid != 1 {
+		t.Errorf("个别越界点不应判为互换: %+v", v)
+	}
+}
+
+func TestAppendValidation(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Validate.Invalid = ActionReject
+	store, err := Open(t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("打开存储失败: %v", err)
+	}
+	defer store.Close()
+
+	points := linePoints(20, 120.0)
+	points[5] = points[4]
+	report, err := store.Append(context.Background(), "a", points)
+	if err != nil || report.Validation.Duplicate != 1 || report.PointsIn != 19 {
+		t.Errorf("重复点应被丢弃: %+v %v", report, err)
+	}
+
+	points[7].Latitude = math.Inf(1)
+	report, err = store.Append(context.Background(), "b", points)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || report.Chunks != 0 {
+		t.Errorf("命中 reject 规则时不应写入: %+v %v", report, err)
+	}
+	if st := store.Stats(); st.Trajectories != 1 {
+		t.Errorf("被拒绝的轨迹不应写入: %+v", st)
+	}
+}
@@ -0,0 +1,299 @@
+package trackstore
+
+import (
+	"context"
+	"math"
+	"log"
+	"path/filepath"
+	"fmt"
+
+)
+
+func interPoints(p1, p2 Point, v1, v2 [2]float64, numPoints int) []Point {
+    answer := make([]Point, numPoints)
+    for i := 0; i < numPoints; i++ {
+        t := float64(i+1) / float64(numPoints+1)
+        h1 := 2*t*t*t - 3*t*t + 1
+        h2 := -2*t*t*t + 3*t*t
+        h3 := t*t*t - 2*t*t + t
+        h4 := t*t*t - t*t
+
+        lon := h1*p1.Longitude + h2*p2.Longitude + h3*v1[0] + h4*v2[0]
+        lat := h1*p1.Latitude + h2*p2.Latitude + h3*v1[1] + h4*v2[1]
+
+        answer[i] = Point{Longitude: lon, Latitude: lat}
+    }
+    return answer
+}
+
+// 两点间的升降速度（米/秒），没有定位时间时与速度检测一样按 1 秒计
+func climbRate(a, b Point) float64 {
+	dt := 1.0
+	if !a.Time.IsZero() && !b.Time.IsZero() && b.Time.After(a.Time) {
+		dt = b.Time.Sub(a.Time).Seconds()
+	}
+	return (b.Altitude - a.Altitude) / dt
+}
+
+// 高程尖刺：进出该点的升降速度都超过 maxRate 且方向相反
+func climbSpike(prev, p, next Point, maxRate float64) bool {
+	if !prev.HasAltitude || !p.HasAltitude || !next.HasAltitude {
+		return false
+	}
+	in, out := climbRate(prev, p), climbRate(p, next)
+	return math.Abs(in) > maxRate && math.Abs(out) > maxRate && in*out < 0
+}
+
+// 水平误差等于 UERE（HDOP 为 1）的定位允许的绕行距离（米），误差越大按 FixWeight 成比例收紧
+const maxDetour = 40.0
+
+// HDOP 较大的点可信度低，按 FixWeight 收紧阈值：速度变化 a 超过 sheld*w，
+// 或经过该点比直接连接前后两点多走的距离（速度突变只会标记尖刺两侧的点）超过 maxDetour*w 米时视为异常
+func lowWeightOutlier(prev, p, next Point, a, sheld, uere float64, distance Metric) bool {
+	w := FixWeight(p, uere)
+	if w >= 1 {
+		return false
+	}
+	detour := distance(prev, p) + distance(p, next) - distance(prev, next)
+	return a > sheld*w || detour > maxDetour*w
+}
+
+// SpeedOutliner 检测并修正数据块中速度突变与高程尖刺的异常点，返回修正后的点（不含重叠部分）及被修正的点数。
+// 两点都有海拔时速度按三维距离计算；MaxClimbRate 检测出的高程尖刺不再做速度检测，
+// 与尖刺相邻的点只按水平距离计算，避免尖刺两侧的正常点被判为速度突变。
+// Clean 为 CleanKalman 时不使用速度突变规则，拒绝误差过大或与预测位置相差过远的定位，
+// 异常点由按精度加权的卡尔曼平滑结果代替，否则在前后正常点之间插值
+func SpeedOutliner(aTask Data) ([]Point, int) {
+	start := aTask.Start
+	end := aTask.End
+	points := aTask.Points
+	lenth := end-start+1
+	horizontal := aTask.Metric.orDefault()
+	distance3D := Metric(func(a, b Point) float64 { return Distance3D(aTask.Metric, a, b) })
+
+	// 检查空切片
+    if len(points) == 0 {
+        log.Println("错误: Points 切片为空")
+        return []Point{}, 0
+    }
+
+    // 验证索引范围
+    if start < 0 || start >= len(points) || end < 0 || end >= len(points) || start > end {
+        // log.Println("完成任务派发")
+        return []Point{}, 0
+    }
+
+	result := make([]Point, lenth)
+	copy(result, points[start : end+1])
+
+	// 重叠部分的点也参与检测，避免用相邻数据块中的异常点作为插值端点
+	isAno := make([]bool, len(points))
+
+	kalman := aTask.Clean == CleanKalman
+	uere := aTask.Kalman.withDefaults().UERE
+	spikes := make([]bool, len(points))
+	if aTask.MaxClimbRate > 0 {
+		for idx := 1; idx < len(points)-1; idx++ {
+			spikes[idx] = climbSpike(points[idx-1], points[idx], points[idx+1], aTask.MaxClimbRate)
+			isAno[idx] = spikes[idx]
+		}
+	}
+	for idx := 1; idx < len(points)-1; idx++ {
+		// 卡尔曼平滑由新息门限与 MaxError 拒绝定位；速度突变规则会标记尖刺两侧的正常点，不再使用
+		if kalman || spikes[idx] {
+			continue
+		}
+		// 相邻点是高程尖刺时高差不可信，只按水平距离计算
+		distance := distance3D
+		if spikes[idx-1] || spikes[idx+1] {
+			distance = horizontal
+		}
+
+		dist1 := distance(points[idx-1], points[idx])
+		v1 := dist1 / 1.0
+
+		dist2 := distance(points[idx], points[idx+1])
+		v2 := dist2 / 1.0
+
+		a := math.Abs(v2 - v1) / 1.0
+
+		const sheld = 10.0
+		if aTask.WeightHDOP && lowWeightOutlier(points[idx-1], points[idx], points[idx+1], a, sheld, uere, distance) {
+			isAno[idx] = true
+		}
+		if a > sheld {
+			isAno[idx] = true
+
+		}
+	}
+	
+	if kalman {
+		// 水平误差超过阈值的定位（包括首尾两点）不可信，由平滑结果代替
+		if opts := aTask.Kalman.withDefaults(); opts.MaxError > 0 {
+			for idx, p := range points {
+				if sigma, ok := HorizontalError(p, opts.UERE); ok && sigma > opts.MaxError {
+					isAno[idx] = true
+				}
+			}
+		}
+		return kalmanRepair(aTask, isAno)
+	}
+
+	var allAno [][]int
+	var aAno []int
+
+	for i := 0; i < len(isAno); i++ {
+		if isAno[i] {
+			aAno = append(aAno, i)
+		} else if len(aAno) > 0 {
+			allAno = append(allAno, aAno)
+			aAno = nil
+		}
+
+	}
+	if len(aAno) > 0 {
+		allAno = append(allAno, aAno)
+	}
+
+	fixed := 0
+	for _, group := range allAno {
+		if len(group) == 0 {
+			log.Println("出现group长度为0！")
+			continue
+		}
+		beginIdx := group[0]
+		lastIdx := group[len(group)-1]
+		numAnomalies := lastIdx - beginIdx + 1
+
+		if numAnomalies <= 0 {
+			log.Println("错误：长度<=0")
+			continue
+		}
+
+		indexP := max(0, beginIdx - 1)
+		indexN := min(len(points)-1, lastIdx + 1)
+		pointP := points[indexP]
+		pointN := points[indexN]
+
+		var v1, v2[2]float64
+		if indexP > 0 {
+			v1 = [2]float64 {
+				points[indexP].Longitude - points[indexP - 1].Longitude,
+				points[indexP].Latitude - points[indexP - 1].Latitude,
+		}
+	}else {
+		v1 = [2]float64{
+			pointN.Longitude - pointP.Longitude,
+			pointN.Latitude - pointN.Latitude,
+		}
+		if indexN < len(points)-1 {
+			v2 = [2]float64{
+				points[indexN+1].Longitude - points[indexN].Longitude,
+				points[indexN+1].Latitude - points[indexN].Latitude,
+			}
+		}else {
+			v2 = [2]float64{
+				pointN.Longitude - pointP.Longitude,
+				pointN.Latitude - pointP.Latitude,
+			}
+		}
+	}
+
+		correctPoints := interPoints(pointP, pointN, v1, v2, numAnomalies)
+		if len(result) !=  lenth{
+			log.Println("result != lenth！长度出错")
+		}
+
+		for j, idx := range group {
+			// 只写回数据块本身的点
+			if idx < start || idx > end {
+				continue
+			}
+			// 插值只修正位置，保留原有的定位时间，并记下修正前的点；海拔在前后两点间线性插值
+			original := result[idx-start]
+			correctPoints[j].Time = original.Time
+			if pointP.HasAltitude && pointN.HasAltitude {
+				t := float64(j+1) / float64(numAnomalies+1)
+				correctPoints[j].Altitude = pointP.Altitude + t*(pointN.Altitude-pointP.Altitude)
+				correctPoints[j].HasAltitude = true
+			}
+			correctPoints[j].Attrs = interpolateAttrs(pointP.Attrs, pointN.Attrs, original.Attrs, float64(j+1)/float64(numAnomalies+1))
+			correctPoints[j].Flag = FlagInterpolated
+			correctPoints[j].Original = &original
+			result[idx-start] = correctPoints[j]
+			fixed++
+		}
+
+	}
+	return result, fixed
+
+}
+
+// ctx 取消后不再领取新任务，正在处理的任务会完成并发出
+func worker_1(ctx context.Context, id int, tasks <-chan Data, results chan<- cleanResult) {
+	for task := range tasks {
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Worker %d 处理任务%d: StartIdx=%d, EndIdx=%d，长度%d", id,task.TaskCode, task.Start, task.End, len(task.Points))
+		processedPoints, fixed := SpeedOutliner(task)
+		results <- cleanResult{
+			TaskIdx:    task.TaskCode,   // 传递任务的 TaskCode
+			Trajectory: task.Trajectory,
+			Seq:        task.Seq,
+			Points:     processedPoints, // 任务处理后的点
+			PointsIn:   task.End - task.Start + 1,
+			Fixed:      fixed,
+		}
+	}
+}
+
+// 将(taskIdx, points)序列化并写入文件，文件头中记下所属轨迹与序号
+func writePoints(taskIdx int, points []Point, trajectory string, seq int, directory string) error {
+	filePath := filepath.Join(directory, fmt.Sprintf("%d.gob", taskIdx))
+	if err := encodeChunk(filePath, points, trajectory, seq); err != nil {
+		return fmt.Errorf("Points 写入失败: %v", err)
+	}
+
+	return nil
+
+}
+
+// worker_2 不监听取消：中断时仍会把已清洗完的数据块写完，避免留下不完整的存储。
+// 每个数据块的处理结果（包括失败原因）都发送到 results
+func worker_2(id int, tasks <-chan cleanResult, results chan<- chunkResult, indexTable *IndexTable, directory string) {
+	for task := range tasks {
+		result := chunkResult{
+			TaskIdx:  task.TaskIdx,
+			PointsIn: task.PointsIn,
+			Fixed:    task.Fixed,
+		}
+		if len(task.Points) <= 0 {
+			result.Skipped = true
+			results <- result
+			continue
+		}
+
+		// 先写入点文件，成功后再登记到索引表，避免索引指向不存在的数据块
+		err := writePoints(task.TaskIdx, task.Points, task.Trajectory, task.Seq, directory)
+		if err != nil {
+			result.Err = &chunkError{TaskIdx: task.TaskIdx, Stage: "写入数据块", Err: err}
+			results <- result
+			continue
+		}
+		// 以经纬度最值作为数据块范围，与 REINDEX 重建的结果一致
+		meta := newChunkMeta(task.TaskIdx, task.Points)
+		meta.Trajectory, meta.Seq = task.Trajectory, task.Seq
+		indexTable.AddChunk(meta)
+		result.PointsOut = len(task.Points)
+
+		// 将 IndexTable 序列化并保存到目录；失败时数据块本身已落盘，结束时还会统一保存一次
+		err = indexTable.SerializeIndexTable(directory)
+		if err != nil {
+			log.Printf("警告: 任务 %d 完成后序列化 IndexTable 失败: %v", task.TaskIdx, err)
+		}
+
+		log.Printf("Worker %d 完成任务 %d", id, task.TaskIdx)
+		results <- result
+	}
+}
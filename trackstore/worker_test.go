@@ -0,0 +1,166 @@
+package trackstore
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkerA(t *testing.T) {
+	testPoints := []Point{
+        {Longitude: 0.0, Latitude: 0.0},   // P0
+        {Longitude: 0.1, Latitude: 0.0},   // P1
+        {Longitude: 0.2, Latitude: 0.0},   // P2
+        {Longitude: 0.3, Latitude: 0.1},   // P3 (异常点)
+        {Longitude: 0.4, Latitude: 0.0},   // P4
+        {Longitude: 0.5, Latitude: 0.0},   // P5
+        {Longitude: 0.6, Latitude: 0.0},   // P6
+        {Longitude: 0.7, Latitude: 0.2},   // P7 (异常点)
+        {Longitude: 0.8, Latitude: 0.0},   // P8
+        {Longitude: 0.9, Latitude: 0.0},   // P9
+    }
+
+    tasks := []Data{
+        {
+            Points:   testPoints[0:5], // P0 ~ P4
+            Start: 1,               // P1
+            End:   3,               // P3
+	    TaskCode: 0, 
+        },
+        {
+            Points:   testPoints[3:8], // P3 ~ P7
+            Start: 1,               // P4
+            End:   3,               // P6
+	    TaskCode: 1, 
+        },
+        {
+            Points:   testPoints[6:10], // P6 ~ P9
+            Start: 1,                // P7
+            End:   2,                // P8
+	    TaskCode: 2, 
+        },
+    }
+
+    taskChan := make(chan Data, len(tasks))
+    resultChan := make(chan cleanResult, len(tasks))
+
+  // Start worker goroutines
+	var wg sync.WaitGroup
+	numWorkers := 3
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			worker_1(context.Background(), id, taskChan, resultChan)
+		}(i)
+	}
+
+	// Send tasks to taskChan
+	for _, task := range tasks {
+		taskChan <- task
+	}
+	close(taskChan)
+
+	// Collect results from resultChan
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	// 速度突变规则标记的是尖刺前后的点，尖刺本身速度变化很小；被标记的点在前后两点之间插值
+	expected := map[int][]Point{
+		0: {{Longitude: 0.1}, {Longitude: 0.2125, Latitude: 0.05, Flag: FlagInterpolated}, {Longitude: 0.3, Latitude: 0.1}},
+		1: {{Longitude: 0.4125, Latitude: 0.05, Flag: FlagInterpolated}, {Longitude: 0.5}, {Longitude: 0.6125, Latitude: 0.1, Flag: FlagInterpolated}},
+		2: {{Longitude: 0.7, Latitude: 0.2}, {Longitude: 0.8125, Latitude: 0.125, Flag: FlagInterpolated}},
+	}
+	fixed := map[int]int{0: 1, 1: 2, 2: 1}
+	for result := range resultChan {
+		want := expected[result.TaskIdx]
+		if len(result.Points) != len(want) || result.Fixed != fixed[result.TaskIdx] {
+			t.Errorf("任务 %d 期望 %d 个点、修正 %d 个，实际 %d 个、修正 %d 个",
+				result.TaskIdx, len(want), fixed[result.TaskIdx], len(result.Points), result.Fixed)
+			continue
+		}
+		for i, p := range result.Points {
+			if p.Flag != want[i].Flag || math.Abs(p.Longitude-want[i].Longitude) > 1e-9 || math.Abs(p.Latitude-want[i].Latitude) > 1e-9 {
+				t.Errorf("任务 %d 的第 %d 个点期望 %+v，实际 %+v", result.TaskIdx, i, want[i], p)
+			}
+			if (p.Flag == FlagInterpolated) != (p.Original != nil) {
+				t.Errorf("任务 %d 的第 %d 个点应且仅应在插值时记下原始点", result.TaskIdx, i)
+			}
+		}
+		delete(expected, result.TaskIdx)
+	}
+	if len(expected) != 0 {
+		t.Errorf("缺少任务结果: %v", expected)
+	}
+}
+
+func TestClimbSpike(t *testing.T) {
+	start := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	points := linePoints(20, 120.0)
+	for i := range points {
+		points[i].Altitude, points[i].HasAltitude = 100, true
+		points[i].Time = start.Add(time.Duration(i) * time.Second)
+	}
+	points[10].Altitude = 400 // 1 秒内升降 300 米
+
+	task := Data{Points: points, Start: 0, End: len(points) - 1}
+	result, fixed := SpeedOutliner(task)
+	if result[10].Altitude != 400 {
+		t.Errorf("未启用升降速度检测时尖刺点不应被修正: %+v", result[10])
+	}
+	// 速度按三维距离计算，进出尖刺的速度突变使其前后两点被标记
+	if fixed != 2 || result[9].Flag != FlagInterpolated || result[11].Flag != FlagInterpolated {
+		t.Errorf("未启用升降速度检测时尖刺前后的点应被判为速度突变: %d %+v %+v", fixed, result[9], result[11])
+	}
+
+	task.MaxClimbRate = 50
+	result, fixed = SpeedOutliner(task)
+	if fixed != 1 || result[10].Flag != FlagInterpolated || !result[10].HasAltitude || math.Abs(result[10].Altitude-100) > 1e-9 {
+		t.Errorf("尖刺点应按前后两点插值海拔: %+v", result[10])
+	}
+	if result[10].Original == nil || result[10].Original.Altitude != 400 {
+		t.Errorf("应记下修正前的海拔: %+v", result[10].Original)
+	}
+	// 尖刺的高差不计入相邻点的速度，尖刺前后的正常点不应被判为速度突变
+	for _, i := range []int{9, 11} {
+		if result[i] != points[i] {
+			t.Errorf("第 %d 个点不应被修改: %+v", i, result[i])
+		}
+	}
+	// 海拔阶跃不是尖刺，仍按三维速度检测
+	step := slices.Clone(points)
+	for i := 10; i < len(step); i++ {
+		step[i].Altitude = 400
+	}
+	if _, fixed := SpeedOutliner(Data{Points: step, Start: 0, End: len(step) - 1, MaxClimbRate: 50}); fixed == 0 {
+		t.Errorf("海拔阶跃应被判为速度突变")
+	}
+	if !climbSpike(points[9], points[10], points[11], 50) || climbSpike(points[9], points[10], points[11], 500) {
+		t.Errorf("升降速度阈值判断不正确")
+	}
+}
+
+func TestOverlapAnomaly(t *testing.T) {
+	// 第 11 个点属于下一个数据块，位于本数据块的重叠部分：水平偏离约 110 米，海拔尖刺 300 米
+	points := linePoints(13, 120.0)
+	for i := range points {
+		points[i].Altitude, points[i].HasAltitude = 100, true
+	}
+	points[11].Latitude += 0.001
+	points[11].Altitude = 400
+
+	task := Data{Points: points, Start: 0, End: 10, MaxClimbRate: 50}
+	result, fixed := SpeedOutliner(task)
+	if len(result) != 11 || fixed != 1 {
+		t.Fatalf("只应修正数据块本身的第 10 个点: %d %d", len(result), fixed)
+	}
+	// 重叠部分的异常点不应作为插值端点
+	if p := result[10]; p.Flag != FlagInterpolated || math.Abs(p.Latitude-30) > 1e-9 || math.Abs(p.Altitude-100) > 1e-9 {
+		t.Errorf("第 10 个点应按正常点插值: %+v", p)
+	}
+}